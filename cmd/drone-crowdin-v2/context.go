@@ -0,0 +1,106 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+	"github.com/lcomrade/drone-crowdin-v2/internal/locale"
+)
+
+// Examples: "max 20 chars", "maxLength: 20", "max-length=20", "maximum 20 characters".
+// A number after "max" is a length only with a unit or the "length" keyword, so "max 3 retries" is not.
+var maxLengthRe = regexp.MustCompile(`(?i)\bmax(?:imum)?(?:[ _-]?(?:length|len)\s*[:=]?\s*(\d+)(?:\s*(?:characters|chars?|symbols)\b)?|\s*[:=]?\s*(\d+)\s*(?:characters|chars?|symbols)\b)`)
+
+var contextPrefixRe = regexp.MustCompile(`(?i)^context\s*:\s*`)
+
+// parseStringComment returns the string context and max length from the source file comment.
+// Example: "context: button on login page, max 20 chars"
+func parseStringComment(comment string) (string, int) {
+	maxLength := 0
+	if match := maxLengthRe.FindStringSubmatch(comment); match != nil {
+		maxLength, _ = strconv.Atoi(match[1] + match[2])
+		comment = strings.Replace(comment, match[0], "", 1)
+	}
+
+	context := strings.Trim(comment, " \t\r\n,;.")
+	context = contextPrefixRe.ReplaceAllString(context, "")
+
+	return strings.TrimSpace(context), maxLength
+}
+
+// applyStringContext sets context and max length of source strings from comments in the source file.
+func applyStringContext(client *crowdin.Client, projectID string, localPath string, cloudFileName string) {
+	if locale.DetectFormat(localPath) == "" {
+		fmt.Println("WARNING: string context: unsupported file format, skipped:", localPath)
+		return
+	}
+
+	entries, err := parseLocaleFile(localPath)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	fileID, err := client.FindFileId(projectID, cloudFileName)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	if fileID == "" {
+		exitOnError("Crowdin file not found:", cloudFileName)
+	}
+
+	strs, err := client.ListStrings(projectID, fileID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	strsByKey := make(map[string]crowdin.SourceString)
+	for _, str := range strs {
+		strsByKey[str.Identifier] = str
+	}
+
+	changed := 0
+	for _, entry := range entries {
+		if entry.Comment == "" {
+			continue
+		}
+
+		str, ok := strsByKey[entry.Key]
+		if !ok {
+			continue
+		}
+
+		context, maxLength := parseStringComment(entry.Comment)
+		ok, err = client.EditString(projectID, str, context, maxLength)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if ok {
+			changed++
+		}
+	}
+
+	fmt.Printf("- Context: %s (%d strings changed)\n", cloudFileName, changed)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+package main
+
+import (
+	"testing"
+)
+
+func TestParseStringComment(t *testing.T) {
+	tests := []struct {
+		comment   string
+		context   string
+		maxLength int
+	}{
+		{"", "", 0},
+		{"Button on login page", "Button on login page", 0},
+		{"context: button on login page, max 20 chars", "button on login page", 20},
+		{"Context: menu title; maxLength: 15", "menu title", 15},
+		{"max-length=10", "", 10},
+		{"Title, maximum 30 characters", "Title", 30},
+		{"Tab name, max_len 8", "Tab name", 8},
+		{"MAX 12 CHARS", "", 12},
+		{"Shown after max 3 retries", "Shown after max 3 retries", 0},
+		{"Maximum 5 files can be uploaded", "Maximum 5 files can be uploaded", 0},
+		{"Max 2 characterset names", "Max 2 characterset names", 0},
+	}
+
+	for _, test := range tests {
+		context, maxLength := parseStringComment(test.comment)
+		if context != test.context || maxLength != test.maxLength {
+			t.Errorf("parseStringComment(%q) = %q, %d, want %q, %d",
+				test.comment, context, maxLength, test.context, test.maxLength)
+		}
+	}
+}
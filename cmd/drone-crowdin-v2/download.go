@@ -0,0 +1,290 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+func targetDownload(client *crowdin.Client, projectID string) {
+	// Get download parameters
+	downloadTo := os.Getenv("PLUGIN_DOWNLOAD_TO")
+	if downloadTo == "" {
+		exitOnError("empty 'download to' parameter")
+	}
+
+	opts := crowdin.DownloadOptions{
+		SkipUntranslatedStrings:          getBoolVal("PLUGIN_DOWNLOAD_SKIP_UNTRANSLATED_STRINGS"),
+		SkipUntranslatedStringsLanguages: getListVal("PLUGIN_DOWNLOAD_SKIP_UNTRANSLATED_STRINGS_LANGUAGES"),
+		SkipUntranslatedFiles:            getBoolVal("PLUGIN_DOWNLOAD_SKIP_UNTRANSLATED_FILES"),
+		ExportApprovedOnly:               getBoolVal("PLUGIN_DOWNLOAD_EXPORT_APPROVED_ONLY"),
+		ExportStringsThatPassedWorkflow:  getBoolVal("PLUGIN_DOWNLOAD_EXPORT_PASSED_WORKFLOW"),
+		ForceRebuild:                     getBoolVal("PLUGIN_DOWNLOAD_FORCE_REBUILD"),
+		PathTemplate:                     os.Getenv("PLUGIN_DOWNLOAD_PATH_TEMPLATE"),
+
+		UseProjectLanguageMapping: getBoolVal("PLUGIN_LANGUAGE_MAPPING_FROM_PROJECT"),
+	}
+
+	if val := os.Getenv("PLUGIN_DOWNLOAD_EXPORT_MIN_APPROVALS"); val != "" {
+		var err error
+		opts.ExportWithMinApprovalsCount, err = strconv.Atoi(val)
+		if err != nil {
+			exitOnError("bad PLUGIN_DOWNLOAD_EXPORT_MIN_APPROVALS parameter value:", val)
+		}
+	}
+
+	if pathMapping := os.Getenv("PLUGIN_DOWNLOAD_PATH_MAPPING"); pathMapping != "" {
+		err := json.Unmarshal([]byte(pathMapping), &opts.PathMapping)
+		if err != nil {
+			exitOnError("failed read download path mapping:", err.Error())
+		}
+	}
+
+	if languageMapping := os.Getenv("PLUGIN_LANGUAGE_MAPPING"); languageMapping != "" {
+		err := json.Unmarshal([]byte(languageMapping), &opts.LanguageMapping)
+		if err != nil {
+			exitOnError("failed read language mapping:", err.Error())
+		}
+	}
+
+	if labels := getListVal("PLUGIN_DOWNLOAD_LABELS"); len(labels) != 0 {
+		opts.LabelIDs = getLabelIDs(client, projectID, labels)
+	}
+
+	if labels := getListVal("PLUGIN_DOWNLOAD_EXCLUDE_LABELS"); len(labels) != 0 {
+		opts.ExcludeLabelIDs = getLabelIDs(client, projectID, labels)
+	}
+
+	if bundle := os.Getenv("PLUGIN_DOWNLOAD_BUNDLE"); bundle != "" {
+		opts.BundleID = bundle
+		if _, err := strconv.ParseUint(bundle, 10, 64); err != nil {
+			bundleID, err := client.FindBundleId(projectID, bundle)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			if bundleID == "" {
+				exitOnError("Crowdin bundle not found:", bundle)
+			}
+			opts.BundleID = bundleID
+		}
+	}
+
+	// Check build options before starting the build
+	err := opts.CheckBuildOptions()
+	if err != nil {
+		exitOnError(err)
+	}
+
+	fmt.Print("Build config:\n" + opts.BuildConfig())
+
+	// Checks of downloaded files
+	validateMode := getCheckVal("PLUGIN_DOWNLOAD_VALIDATE")
+	placeholdersMode := getCheckVal("PLUGIN_DOWNLOAD_CHECK_PLACEHOLDERS")
+
+	sources := make(map[string]string)
+	if placeholdersMode != checkOff {
+		if os.Getenv("PLUGIN_UPLOAD_FILES") != "" {
+			for localPath, file := range getUploadFiles() {
+				sources[file.Name] = localPath
+			}
+		} else {
+			fmt.Println("WARNING: placeholders check: upload files list is not set, only gettext PO and XLIFF files are checked")
+		}
+	}
+
+	if validateMode != checkOff || placeholdersMode != checkOff {
+		opts.Validate = func(dir string, files []crowdin.ExtractedFile) error {
+			if validateMode != checkOff {
+				err := validateFiles(validateMode, dir, files)
+				if err != nil {
+					return err
+				}
+			}
+
+			if placeholdersMode != checkOff {
+				return checkPlaceholders(placeholdersMode, dir, files, sources)
+			}
+
+			return nil
+		}
+	}
+
+	pruneManifest := os.Getenv("PLUGIN_DOWNLOAD_PRUNE_MANIFEST")
+	prunePatterns := getListVal("PLUGIN_DOWNLOAD_PRUNE_PATTERNS")
+
+	// Source language files are never in the archive, so they must be known
+	if len(prunePatterns) != 0 && os.Getenv("PLUGIN_UPLOAD_FILES") == "" {
+		exitOnError("download prune patterns require upload files list, so source files are not deleted")
+	}
+	changesFile := os.Getenv("PLUGIN_DOWNLOAD_CHANGES_FILE")
+
+	// Download
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	var extracted []crowdin.ExtractedFile
+	if project.Type == crowdin.ProjectTypeStrings {
+		extracted, err = client.DownloadStrings(downloadTo, projectID, getStringsFiles(), opts)
+	} else {
+		extracted, err = client.Download(downloadTo, projectID, opts)
+	}
+	if err != nil {
+		exitOnError(err)
+	}
+
+	var changes []string
+	stats := make(map[string]int)
+	for _, part := range extracted {
+		fmt.Println("- Extract:", part.Name, "->", filepath.Join(downloadTo, part.Path), "("+part.Status+")")
+
+		stats[part.Status]++
+		if part.Status != crowdin.StatusUnchanged {
+			changes = append(changes, filepath.Join(downloadTo, part.Path))
+		}
+	}
+
+	// Remove translations that no longer exist in Crowdin
+	var deleted []string
+	if pruneManifest != "" || len(prunePatterns) != 0 {
+		deleted = pruneStaleFiles(downloadTo, extracted, pruneManifest, prunePatterns)
+		changes = append(changes, deleted...)
+	}
+
+	fmt.Printf("Summary: %d new, %d changed, %d unchanged, %d deleted\n", stats[crowdin.StatusNew], stats[crowdin.StatusChanged], stats[crowdin.StatusUnchanged], len(deleted))
+
+	// Save changed files list for the next pipeline steps
+	if changesFile != "" {
+		content := ""
+		for _, part := range changes {
+			content += part + "\n"
+		}
+
+		err = os.WriteFile(changesFile, []byte(content), 0644)
+		if err != nil {
+			exitOnError("failed write download changes file:", err.Error())
+		}
+	}
+}
+
+// pruneStaleFiles returns the paths of deleted files.
+func pruneStaleFiles(downloadTo string, extracted []crowdin.ExtractedFile, manifestPath string, patterns []string) []string {
+	keep := make(map[string]bool)
+	var downloaded []string
+	for _, part := range extracted {
+		keep[part.Path] = true
+		downloaded = append(downloaded, part.Path)
+	}
+
+	// Files produced by the previous download
+	var candidates []string
+	if manifestPath != "" {
+		prev, err := readManifest(manifestPath)
+		if err != nil {
+			exitOnError("failed read download manifest:", err.Error())
+		}
+		candidates = append(candidates, prev...)
+	}
+
+	// Files that match prune patterns
+	if len(patterns) != 0 {
+		matched, err := findMatchingFiles(downloadTo, patterns)
+		if err != nil {
+			exitOnError(err)
+		}
+		candidates = append(candidates, matched...)
+	}
+
+	// Never remove source files
+	sources := make(map[string]bool)
+	if os.Getenv("PLUGIN_UPLOAD_FILES") != "" {
+		for localPath := range getUploadFiles() {
+			abs, err := filepath.Abs(localPath)
+			if err != nil {
+				exitOnError(err)
+			}
+			sources[abs] = true
+		}
+	}
+
+	// Never remove the manifest itself
+	manifestRel := ""
+	if manifestPath != "" {
+		rel, err := filepath.Rel(downloadTo, manifestPath)
+		if err == nil {
+			manifestRel = filepath.ToSlash(rel)
+		}
+	}
+
+	var deleted []string
+	removed := make(map[string]bool)
+	for _, rel := range candidates {
+		rel = path.Clean(rel)
+		if keep[rel] || removed[rel] || rel == manifestRel {
+			continue
+		}
+
+		if rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+			exitOnError("download manifest contains a path outside of the download directory:", rel)
+		}
+
+		_, err := os.Lstat(filepath.Join(downloadTo, filepath.FromSlash(rel)))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+
+		abs, err := filepath.Abs(filepath.Join(downloadTo, filepath.FromSlash(rel)))
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if sources[abs] {
+			fmt.Println("- Keep:  ", filepath.Join(downloadTo, rel), "(source file)")
+			continue
+		}
+
+		fmt.Println("- Delete:", filepath.Join(downloadTo, rel))
+		err = removeFile(downloadTo, rel)
+		if err != nil {
+			exitOnError(err)
+		}
+		removed[rel] = true
+		deleted = append(deleted, filepath.Join(downloadTo, rel))
+	}
+
+	if manifestPath != "" {
+		err := writeManifest(manifestPath, downloaded)
+		if err != nil {
+			exitOnError("failed write download manifest:", err.Error())
+		}
+	}
+
+	return deleted
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+func getGlossary(client *crowdin.Client, projectID string) string {
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	name := os.Getenv("PLUGIN_GLOSSARY_NAME")
+	if name == "" {
+		name = project.Name
+	}
+
+	glossaryID, created, err := client.FindOrCreateGlossary(project, name)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	if created {
+		fmt.Println("Crowdin glossary created:", name, "(ID: "+glossaryID+")")
+	}
+
+	return glossaryID
+}
+
+func targetGlossaryUpload(client *crowdin.Client, projectID string) {
+	// Get glossary parameters
+	glossaryFile := os.Getenv("PLUGIN_GLOSSARY_FILE")
+	if glossaryFile == "" {
+		exitOnError("empty 'glossary file' parameter")
+	}
+
+	var scheme map[string]int
+	if schemeStr := os.Getenv("PLUGIN_GLOSSARY_SCHEME"); schemeStr != "" {
+		err := json.Unmarshal([]byte(schemeStr), &scheme)
+		if err != nil {
+			exitOnError("failed read glossary scheme:", err.Error())
+		}
+	}
+
+	firstLineHeader := getBoolVal("PLUGIN_GLOSSARY_FIRST_LINE_HEADER")
+
+	// Import
+	glossaryID := getGlossary(client, projectID)
+
+	fmt.Println("- Import:", glossaryFile, "-> glossary", glossaryID)
+	err := client.ImportGlossary(glossaryID, glossaryFile, scheme, firstLineHeader)
+	if err != nil {
+		exitOnError(err)
+	}
+}
+
+func targetGlossaryDownload(client *crowdin.Client, projectID string) {
+	// Get glossary parameters
+	glossaryFile := os.Getenv("PLUGIN_GLOSSARY_FILE")
+	if glossaryFile == "" {
+		exitOnError("empty 'glossary file' parameter")
+	}
+
+	// Export
+	glossaryID := getGlossary(client, projectID)
+
+	fmt.Println("- Export: glossary", glossaryID, "->", glossaryFile)
+	err := client.ExportGlossary(glossaryID, glossaryFile)
+	if err != nil {
+		exitOnError(err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+// getUploadLabels returns label titles with %DRONE_*% variables expanded.
+func getUploadLabels() []string {
+	var labels []string
+	for _, title := range getListVal("PLUGIN_UPLOAD_LABELS") {
+		expanded := expandDroneVars(title)
+		if expanded == "" {
+			fmt.Println("WARNING: label '" + title + "' is empty, skipped")
+			continue
+		}
+
+		labels = append(labels, expanded)
+	}
+
+	return labels
+}
+
+// changedStrings returns IDs of strings that are new or have another text than before.
+func changedStrings(before []crowdin.SourceString, after []crowdin.SourceString) []string {
+	oldText := make(map[string]string)
+	for _, str := range before {
+		oldText[str.Identifier] = str.Text
+	}
+
+	var ids []string
+	for _, str := range after {
+		text, ok := oldText[str.Identifier]
+		if (!ok || text != str.Text) && !str.IsHidden {
+			ids = append(ids, str.ID)
+		}
+	}
+
+	return ids
+}
+
+// labelStrings finds or creates the labels and assigns them to the strings.
+func labelStrings(client *crowdin.Client, projectID string, titles []string, stringIDs []string) {
+	if len(stringIDs) == 0 {
+		fmt.Println("- Label: no new or changed strings")
+		return
+	}
+	sort.Strings(stringIDs)
+
+	labels, err := client.ListLabels(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	for _, title := range titles {
+		labelID, ok := labels[title]
+		if !ok {
+			labelID, err = client.AddLabel(projectID, title)
+			if err != nil {
+				exitOnError(err)
+			}
+			labels[title] = labelID
+		}
+
+		fmt.Printf("- Label: %s (%d strings)\n", title, len(stringIDs))
+		err = client.AssignLabel(projectID, labelID, stringIDs)
+		if err != nil {
+			exitOnError(err)
+		}
+	}
+}
+
+// getLabelIDs returns IDs of existing labels by their titles.
+func getLabelIDs(client *crowdin.Client, projectID string, titles []string) []string {
+	labels, err := client.ListLabels(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	var ids []string
+	for _, title := range titles {
+		labelID, ok := labels[expandDroneVars(title)]
+		if !ok {
+			exitOnError("Crowdin label not found:", expandDroneVars(title))
+		}
+
+		ids = append(ids, labelID)
+	}
+
+	return ids
+}
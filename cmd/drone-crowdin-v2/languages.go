@@ -0,0 +1,139 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+// readLanguagesFile reads language IDs separated by commas, spaces or new lines.
+// Lines starting with '#' are comments.
+func readLanguagesFile(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var languages []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		languages = append(languages, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})...)
+	}
+
+	return languages, nil
+}
+
+func targetLanguages(client *crowdin.Client, projectID string) {
+	// Get languages list from parameters
+	languages := getListVal("PLUGIN_LANGUAGES")
+	languagesFile := os.Getenv("PLUGIN_LANGUAGES_FILE")
+
+	if languagesFile != "" {
+		if len(languages) != 0 {
+			exitOnError("languages list and languages file cannot be set at the same time")
+		}
+
+		var err error
+		languages, err = readLanguagesFile(languagesFile)
+		if err != nil {
+			exitOnError("failed read languages file:", err.Error())
+		}
+	}
+
+	if len(languages) == 0 {
+		exitOnError("languages list cannot be empty")
+	}
+
+	reportOnly := getBoolVal("PLUGIN_LANGUAGES_REPORT_ONLY")
+
+	// Compare with the project
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	wanted := make(map[string]bool)
+	var target []string
+	for _, lang := range languages {
+		if lang == project.SourceLanguageID {
+			exitOnError("source language cannot be a target language:", lang)
+		}
+
+		if !wanted[lang] {
+			wanted[lang] = true
+			target = append(target, lang)
+		}
+	}
+
+	current := make(map[string]bool)
+	for _, lang := range project.TargetLanguageIDs {
+		current[lang] = true
+	}
+
+	var added, removed []string
+	for _, lang := range target {
+		if !current[lang] {
+			added = append(added, lang)
+		}
+	}
+
+	for _, lang := range project.TargetLanguageIDs {
+		if !wanted[lang] {
+			removed = append(removed, lang)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	for _, lang := range added {
+		fmt.Println("- Add language:   ", lang)
+	}
+
+	for _, lang := range removed {
+		fmt.Println("- Remove language:", lang)
+	}
+
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Println("Target languages are up to date")
+		return
+	}
+
+	if reportOnly {
+		fmt.Println("Report only mode, the project is not changed")
+		return
+	}
+
+	sort.Strings(target)
+	err = client.SetTargetLanguages(projectID, target)
+	if err != nil {
+		exitOnError(err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+	"github.com/lcomrade/drone-crowdin-v2/internal/locale"
+)
+
+const (
+	severityWarning = "warning"
+	severityError   = "error"
+)
+
+var severityLevel = map[string]int{
+	severityWarning: 1,
+	severityError:   2,
+}
+
+type lintProblem struct {
+	severity string
+	file     string
+	line     int
+	key      string
+	msg      string
+}
+
+func (problem lintProblem) String() string {
+	s := "[" + problem.severity + "] " + problem.file
+	if problem.line != 0 {
+		s += ": line " + strconv.Itoa(problem.line)
+	}
+
+	return s + ": " + problem.key + ": " + problem.msg
+}
+
+// lintSourceFile checks the source file for problems that waste translator time.
+func lintSourceFile(localPath string) ([]lintProblem, []locale.Entry, error) {
+	entries, err := parseLocaleFile(localPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var problems []lintProblem
+	add := func(severity string, entry locale.Entry, msg string) {
+		problems = append(problems, lintProblem{
+			severity: severity,
+			file:     localPath,
+			line:     entry.Line,
+			key:      entry.Key,
+			msg:      msg,
+		})
+	}
+
+	firstLine := make(map[string]int)
+	for _, entry := range entries {
+		if line, ok := firstLine[entry.Key]; ok {
+			add(severityError, entry, "duplicate key (first defined at line "+strconv.Itoa(line)+")")
+		} else {
+			firstLine[entry.Key] = entry.Line
+		}
+
+		// Bilingual formats keep the source text separately
+		text := entry.Value
+		if entry.Source != "" {
+			text = entry.Source
+		}
+
+		if strings.TrimSpace(text) == "" {
+			add(severityWarning, entry, "empty value")
+			continue
+		}
+
+		if strings.TrimRight(text, " \t") != text {
+			add(severityWarning, entry, "trailing whitespace")
+		}
+
+		if strings.ContainsAny(text, "{}") {
+			err = locale.CheckICU(text)
+			if err != nil {
+				add(severityError, entry, "bad ICU message syntax: "+err.Error())
+			}
+		}
+	}
+
+	return problems, entries, nil
+}
+
+// lintRemovedStrings finds strings that are removed from the source file but have translations in Crowdin.
+func lintRemovedStrings(client *crowdin.Client, projectID string, languages []crowdin.Language, fileID string, localPath string, entries []locale.Entry, minTranslations int) ([]lintProblem, error) {
+	keys := make(map[string]bool)
+	for _, entry := range entries {
+		keys[entry.Key] = true
+	}
+
+	strs, err := client.ListStrings(projectID, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []crowdin.SourceString
+	var removedIDs []string
+	for _, str := range strs {
+		if str.Identifier != "" && !keys[str.Identifier] {
+			removed = append(removed, str)
+			removedIDs = append(removedIDs, str.ID)
+		}
+	}
+
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	translations := make(map[string]int)
+	for _, lang := range languages {
+		counts, err := client.CountTranslations(projectID, lang.ID, removedIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for stringID, n := range counts {
+			translations[stringID] += n
+		}
+	}
+
+	var problems []lintProblem
+	for _, str := range removed {
+		if translations[str.ID] >= minTranslations {
+			problems = append(problems, lintProblem{
+				severity: severityWarning,
+				file:     localPath,
+				key:      str.Identifier,
+				msg:      "removed, but has " + strconv.Itoa(translations[str.ID]) + " translation(s) in Crowdin",
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// lintUpload checks the source files before upload and fails if there are problems
+// with the severity at or above the threshold.
+func lintUpload(client *crowdin.Client, projectID string, targetFiles map[string]uploadFile, cloudFilesByName map[string]crowdin.File) {
+	failOn := os.Getenv("PLUGIN_UPLOAD_LINT_FAIL_ON")
+	if failOn == "" {
+		failOn = severityError
+	}
+
+	if _, ok := severityLevel[failOn]; !ok && failOn != "none" {
+		exitOnError("bad PLUGIN_UPLOAD_LINT_FAIL_ON parameter value (possible values: error, warning, none)")
+	}
+
+	minTranslations := 5
+	if val := os.Getenv("PLUGIN_UPLOAD_LINT_REMOVED_TRANSLATIONS"); val != "" {
+		var err error
+		minTranslations, err = strconv.Atoi(val)
+		if err != nil || minTranslations < 1 {
+			exitOnError("bad PLUGIN_UPLOAD_LINT_REMOVED_TRANSLATIONS parameter value")
+		}
+	}
+
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	var problems []lintProblem
+	for localPath, file := range targetFiles {
+		if locale.DetectFormat(localPath) == "" {
+			fmt.Println("WARNING: lint: unsupported file format, skipped:", localPath)
+			continue
+		}
+
+		fileProblems, entries, err := lintSourceFile(localPath)
+		if err != nil {
+			exitOnError("lint:", err)
+		}
+		problems = append(problems, fileProblems...)
+
+		cloudFile, exist := cloudFilesByName[file.Name]
+		if !exist {
+			continue
+		}
+
+		fileProblems, err = lintRemovedStrings(client, projectID, project.TargetLanguages, cloudFile.ID, localPath, entries, minTranslations)
+		if err != nil {
+			exitOnError(err)
+		}
+		problems = append(problems, fileProblems...)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].file != problems[j].file {
+			return problems[i].file < problems[j].file
+		}
+		return problems[i].line < problems[j].line
+	})
+
+	failed := 0
+	for _, problem := range problems {
+		fmt.Println("- Lint:", problem)
+
+		if failOn != "none" && severityLevel[problem.severity] >= severityLevel[failOn] {
+			failed++
+		}
+	}
+
+	fmt.Println("Lint summary:", len(problems), "problem(s)")
+
+	if failed != 0 {
+		exitOnError(errors.New("lint: " + strconv.Itoa(failed) + " problem(s) with severity '" + failOn + "' or higher"))
+	}
+}
@@ -19,11 +19,12 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
 )
@@ -55,86 +56,67 @@ func getBoolVal(envName string) bool {
 	return val
 }
 
-func targetUpload(client *crowdin.Client, projectID string) {
-	// Get files list from parameters
-	filesList := os.Getenv("PLUGIN_UPLOAD_FILES")
-
-	if filesList == "" {
-		exitOnError("upload files list cannot be empty")
+// getBoolPtrVal returns nil if the parameter is not set.
+func getBoolPtrVal(envName string) *bool {
+	if os.Getenv(envName) == "" {
+		return nil
 	}
 
-	targetFiles := make(map[string]string)
-	err := json.Unmarshal([]byte(filesList), &targetFiles)
-	if err != nil {
-		exitOnError("failed read upload files list:", err.Error())
-	}
+	val := getBoolVal(envName)
+	return &val
+}
 
-	if len(filesList) == 0 {
-		exitOnError("upload files list cannot be empty")
+func getListVal(envName string) []string {
+	valStr := os.Getenv(envName)
+	if valStr == "" {
+		return nil
 	}
 
-	cloudBadSymbols := string(crowdin.BadSymbols)
-	for localPath, cloudName := range targetFiles {
-		if localPath == "" {
-			exitOnError("local file path cannot be empty")
-		}
-
-		if cloudName == "" {
-			exitOnError("Crowdin file name cannot be empty")
-		}
-
-		if strings.ContainsAny(cloudName, cloudBadSymbols) {
-			exitOnError("Crowdin file name cannot contain '"+cloudBadSymbols+"':", cloudName)
+	var val []string
+	for _, part := range strings.Split(valStr, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			val = append(val, part)
 		}
 	}
 
-	// Add or update files
-	for localPath, cloudName := range targetFiles {
-		// Check file exist in Crowdin
-		fileID, err := client.FindFileId(projectID, cloudName)
-		if err != nil {
-			exitOnError(err)
-		}
+	return val
+}
 
-		// Add if not exist
-		if fileID == "" {
-			fmt.Println("- Add:   ", localPath, "->", cloudName)
-			err = client.AddFile(projectID, localPath, cloudName)
-			if err != nil {
-				exitOnError(err)
-			}
+var droneVarRe = regexp.MustCompile(`%(DRONE_[A-Z0-9_]+)%`)
 
-			// Else update file
-		} else {
-			fmt.Println("- Update:", localPath, "->", cloudName)
-			err = client.UpdateFile(projectID, localPath, cloudName, fileID)
-			if err != nil {
-				exitOnError(err)
-			}
-		}
-	}
+// expandDroneVars replaces %DRONE_*% variables with their values.
+func expandDroneVars(s string) string {
+	return droneVarRe.ReplaceAllStringFunc(s, func(match string) string {
+		return os.Getenv(strings.Trim(match, "%"))
+	})
 }
 
-func targetDownload(client *crowdin.Client, projectID string) {
-	// Get download parameters
-	downloadTo := os.Getenv("PLUGIN_DOWNLOAD_TO")
-	if downloadTo == "" {
-		exitOnError("empty 'download to' parameter")
+func createProject(client *crowdin.Client, name string) string {
+	opts := crowdin.ProjectOptions{
+		Name:              name,
+		SourceLanguageID:  os.Getenv("PLUGIN_CREATE_PROJECT_SOURCE_LANGUAGE"),
+		TargetLanguageIDs: getListVal("PLUGIN_CREATE_PROJECT_TARGET_LANGUAGES"),
+		Visibility:        os.Getenv("PLUGIN_CREATE_PROJECT_VISIBILITY"),
+		QACheckIsActive:   getBoolPtrVal("PLUGIN_CREATE_PROJECT_QA_CHECKS"),
+		UseGlobalTM:       getBoolPtrVal("PLUGIN_CREATE_PROJECT_USE_GLOBAL_TM"),
 	}
 
-	skipUntranslatedStrings := getBoolVal("PLUGIN_DOWNLOAD_SKIP_UNTRANSLATED_STRINGS")
-	skipUntranslatedFiles := getBoolVal("PLUGIN_DOWNLOAD_SKIP_UNTRANSLATED_FILES")
-	exportApprovedOnly := getBoolVal("PLUGIN_DOWNLOAD_EXPORT_APPROVED_ONLY")
+	if opts.SourceLanguageID == "" {
+		exitOnError("empty 'create project source language' parameter")
+	}
+
+	if opts.Visibility != "" && opts.Visibility != "private" && opts.Visibility != "open" {
+		exitOnError("bad PLUGIN_CREATE_PROJECT_VISIBILITY parameter value (possible values: private, open)")
+	}
 
-	// Download
-	extracted, err := client.Download(downloadTo, projectID, skipUntranslatedStrings, skipUntranslatedFiles, exportApprovedOnly)
+	projectID, err := client.CreateProject(opts)
 	if err != nil {
 		exitOnError(err)
 	}
 
-	for _, part := range extracted {
-		fmt.Println("- Extract:", part, "->", filepath.Join(downloadTo, part))
-	}
+	fmt.Println("Crowdin project created:", name, "(ID: "+projectID+")")
+	return projectID
 }
 
 func main() {
@@ -159,9 +141,39 @@ func main() {
 		exitOnError("Crowdin project ID or name not set")
 	}
 
+	if projectID != "" {
+		_, err = strconv.ParseUint(projectID, 10, 64)
+		if err != nil {
+			exitOnError("Crowdin project ID must be a number:", projectID)
+		}
+	}
+
 	// Prepare Crowdin API client
 	crowdin := crowdin.NewClient(key)
 
+	if cacheFile := os.Getenv("PLUGIN_CACHE_FILE"); cacheFile != "" {
+		cacheTTL := time.Hour
+		if val := os.Getenv("PLUGIN_CACHE_TTL"); val != "" {
+			cacheTTL, err = time.ParseDuration(val)
+			if err != nil {
+				exitOnError("bad PLUGIN_CACHE_TTL parameter value:", err.Error())
+			}
+		}
+
+		err = crowdin.SetCache(cacheFile, cacheTTL)
+		if err != nil {
+			exitOnError(err)
+		}
+	}
+
+	// Check that the project is accessible before doing work
+	if projectID != "" {
+		_, err = crowdin.GetProject(projectID)
+		if err != nil {
+			exitOnError("Crowdin project is not accessible:", err)
+		}
+	}
+
 	// Get project ID if need
 	if projectID == "" {
 		projectID, err = crowdin.FindProjectIdByName(projectName)
@@ -169,6 +181,14 @@ func main() {
 			exitOnError(err)
 		}
 
+		if projectID == "" {
+			if !getBoolVal("PLUGIN_CREATE_PROJECT") {
+				exitOnError("Crowdin project name not found: " + projectName)
+			}
+
+			projectID = createProject(crowdin, projectName)
+		}
+
 		tipProjectID = true
 	}
 
@@ -180,8 +200,24 @@ func main() {
 		targetUpload(crowdin, projectID)
 	case "download":
 		targetDownload(crowdin, projectID)
+	case "preview":
+		targetPreview(crowdin, projectID)
+	case "languages":
+		targetLanguages(crowdin, projectID)
+	case "glossary_upload":
+		targetGlossaryUpload(crowdin, projectID)
+	case "glossary_download":
+		targetGlossaryDownload(crowdin, projectID)
+	case "tm_upload":
+		targetTmUpload(crowdin, projectID)
+	case "tm_download":
+		targetTmDownload(crowdin, projectID)
+	case "screenshots":
+		targetScreenshots(crowdin, projectID)
+	case "pretranslate":
+		targetPreTranslate(crowdin, projectID)
 	default:
-		exitOnError("unknown target '" + target + "' (possible targets: upload, download)")
+		exitOnError("unknown target '" + target + "' (possible targets: upload, download, preview, languages, glossary_upload, glossary_download, tm_upload, tm_download, screenshots, pretranslate)")
 	}
 
 	// Tips
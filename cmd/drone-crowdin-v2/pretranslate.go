@@ -0,0 +1,126 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+var autoApproveOptions = map[string]string{
+	"none":                    "none",
+	"all":                     "all",
+	"except_auto_substituted": "exceptAutoSubstituted",
+	"perfect_match_only":      "perfectMatchOnly",
+}
+
+// targetPreTranslate pre-translates files from upload_files.
+func targetPreTranslate(client *crowdin.Client, projectID string) {
+	// Get pre-translation parameters
+	opts := crowdin.PreTranslateOptions{
+		LanguageIDs:                   getListVal("PLUGIN_PRETRANSLATE_LANGUAGES"),
+		Method:                        os.Getenv("PLUGIN_PRETRANSLATE_METHOD"),
+		EngineID:                      os.Getenv("PLUGIN_PRETRANSLATE_ENGINE_ID"),
+		DuplicateTranslations:         getBoolVal("PLUGIN_PRETRANSLATE_DUPLICATE_TRANSLATIONS"),
+		TranslateUntranslatedOnly:     true,
+		TranslateWithPerfectMatchOnly: getBoolVal("PLUGIN_PRETRANSLATE_PERFECT_MATCH_ONLY"),
+	}
+
+	// Do not overwrite existing translations by default
+	if untranslatedOnly := getBoolPtrVal("PLUGIN_PRETRANSLATE_UNTRANSLATED_ONLY"); untranslatedOnly != nil {
+		opts.TranslateUntranslatedOnly = *untranslatedOnly
+	}
+
+	if opts.Method == "" {
+		opts.Method = "tm"
+	}
+
+	switch opts.Method {
+	case "tm":
+		if opts.EngineID != "" {
+			exitOnError("PLUGIN_PRETRANSLATE_ENGINE_ID can only be used with 'mt' pre-translation method")
+		}
+	case "mt":
+		if opts.EngineID == "" {
+			exitOnError("empty 'pretranslate engine ID' parameter")
+		}
+	default:
+		exitOnError("bad PLUGIN_PRETRANSLATE_METHOD parameter value (possible values: tm, mt)")
+	}
+
+	if val := os.Getenv("PLUGIN_PRETRANSLATE_AUTO_APPROVE"); val != "" {
+		option, ok := autoApproveOptions[val]
+		if !ok {
+			exitOnError("bad PLUGIN_PRETRANSLATE_AUTO_APPROVE parameter value (possible values: none, all, except_auto_substituted, perfect_match_only)")
+		}
+		opts.AutoApproveOption = option
+	}
+
+	// Languages and files
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	if project.Type == crowdin.ProjectTypeStrings {
+		exitOnError("pre-translation is not supported for string-based projects")
+	}
+
+	if len(opts.LanguageIDs) == 0 {
+		opts.LanguageIDs = project.TargetLanguageIDs
+	}
+
+	for _, file := range getUploadFiles() {
+		fileID, err := client.FindFileId(projectID, file.Name)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if fileID == "" {
+			exitOnError("Crowdin file not found:", file.Name)
+		}
+
+		opts.FileIDs = append(opts.FileIDs, fileID)
+	}
+	sort.Strings(opts.FileIDs)
+
+	// Pre-translate
+	fmt.Println("- Pre-translate:", len(opts.FileIDs), "files via", opts.Method)
+	preID, err := client.PreTranslate(projectID, opts)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	report, err := client.PreTranslateReport(projectID, preID)
+	if err != nil {
+		fmt.Println("WARNING: failed get pre-translation report:", err.Error())
+		return
+	}
+
+	total := 0
+	for _, lang := range opts.LanguageIDs {
+		fmt.Printf("- Pre-translated: %s (%d strings)\n", lang, report[lang])
+		total += report[lang]
+	}
+
+	fmt.Printf("Summary: %d strings pre-translated\n", total)
+}
@@ -0,0 +1,244 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+	"github.com/lcomrade/drone-crowdin-v2/internal/locale"
+)
+
+type stringChange struct {
+	key     string
+	oldText string
+	newText string
+}
+
+func markdownCell(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	s = strings.Replace(s, "\r\n", "<br>", -1)
+	s = strings.Replace(s, "\n", "<br>", -1)
+	return s
+}
+
+func markdownCode(s string) string {
+	if strings.Contains(s, "`") {
+		return "`` " + s + " ``"
+	}
+
+	return "`" + s + "`"
+}
+
+// previewSection returns the report section with string changes.
+func previewSection(title string, note string, added []stringChange, changed []stringChange, removed []stringChange) string {
+	section := "\n### " + title + "\n"
+	if note != "" {
+		section += "\n" + note + "\n"
+	}
+
+	if len(added) != 0 {
+		section += "\n**Added (" + strconv.Itoa(len(added)) + ")**\n\n| Key | Text |\n| --- | --- |\n"
+		for _, change := range added {
+			section += "| " + markdownCode(change.key) + " | " + markdownCell(change.newText) + " |\n"
+		}
+	}
+
+	if len(changed) != 0 {
+		section += "\n**Changed (" + strconv.Itoa(len(changed)) + ")**\n\n| Key | Old text | New text |\n| --- | --- | --- |\n"
+		for _, change := range changed {
+			section += "| " + markdownCode(change.key) + " | " + markdownCell(change.oldText) + " | " + markdownCell(change.newText) + " |\n"
+		}
+	}
+
+	if len(removed) != 0 {
+		section += "\n**Removed (" + strconv.Itoa(len(removed)) + ")**\n\n| Key | Text |\n| --- | --- |\n"
+		for _, change := range removed {
+			section += "| " + markdownCode(change.key) + " | " + markdownCell(change.oldText) + " |\n"
+		}
+	}
+
+	return section
+}
+
+// listPreviewStrings returns texts of Crowdin strings by key and the keys in Crowdin order.
+// In string-based projects hidden strings are skipped, because removed strings are hidden on upload.
+func listPreviewStrings(client *crowdin.Client, projectID string, fileID string, skipHidden bool) (map[string]string, []string) {
+	strs, err := client.ListStrings(projectID, fileID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	texts := make(map[string]string)
+	var keys []string
+	for _, str := range strs {
+		if skipHidden && str.IsHidden {
+			continue
+		}
+
+		if _, ok := texts[str.Identifier]; !ok {
+			keys = append(keys, str.Identifier)
+		}
+		texts[str.Identifier] = str.Text
+	}
+
+	return texts, keys
+}
+
+func targetPreview(client *crowdin.Client, projectID string) {
+	targetFiles := getUploadFiles()
+
+	outputFile := os.Getenv("PLUGIN_PREVIEW_FILE")
+	if outputFile == "" {
+		outputFile = "crowdin-preview.md"
+	}
+
+	// String-based projects have no files, all strings are compared at once
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+	stringsProject := project.Type == crowdin.ProjectTypeStrings
+
+	var projectTexts map[string]string
+	var projectKeys []string
+	if stringsProject {
+		projectTexts, projectKeys = listPreviewStrings(client, projectID, "", true)
+	}
+
+	// Sort files by Crowdin file name
+	var localPaths []string
+	for localPath := range targetFiles {
+		localPaths = append(localPaths, localPath)
+	}
+	sort.Slice(localPaths, func(i, j int) bool {
+		return targetFiles[localPaths[i]].Name < targetFiles[localPaths[j]].Name
+	})
+
+	report := "## Crowdin string changes\n"
+	total := 0
+	allLocalKeys := make(map[string]bool)
+
+	for _, localPath := range localPaths {
+		cloudName := targetFiles[localPath].Name
+
+		if locale.DetectFormat(localPath) == "" {
+			fmt.Println("WARNING: preview: unsupported file format, skipped:", localPath)
+			continue
+		}
+
+		// Local strings
+		entries, err := parseLocaleFile(localPath)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		// Crowdin strings
+		fileID := ""
+		cloudTexts, cloudKeys := projectTexts, []string(nil)
+		if !stringsProject {
+			fileID, err = client.FindFileId(projectID, cloudName)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			cloudTexts = make(map[string]string)
+			if fileID != "" {
+				cloudTexts, cloudKeys = listPreviewStrings(client, projectID, fileID, false)
+			}
+		}
+
+		// Compare
+		var added, changed, removed []stringChange
+		localKeys := make(map[string]bool)
+		for _, entry := range entries {
+			if localKeys[entry.Key] {
+				continue
+			}
+			localKeys[entry.Key] = true
+			allLocalKeys[entry.Key] = true
+
+			text := entry.Value
+			if entry.Source != "" {
+				text = entry.Source
+			}
+
+			oldText, exist := cloudTexts[entry.Key]
+			switch {
+			case !exist:
+				added = append(added, stringChange{key: entry.Key, newText: text})
+			case oldText != text:
+				changed = append(changed, stringChange{key: entry.Key, oldText: oldText, newText: text})
+			}
+		}
+
+		for _, key := range cloudKeys {
+			if !localKeys[key] {
+				removed = append(removed, stringChange{key: key, oldText: cloudTexts[key]})
+			}
+		}
+
+		fmt.Println("- Preview:", localPath, "->", cloudName+":", len(added), "added,", len(changed), "changed,", len(removed), "removed")
+
+		count := len(added) + len(changed) + len(removed)
+		if count == 0 {
+			continue
+		}
+		total += count
+
+		note := ""
+		if !stringsProject && fileID == "" {
+			note = "New file, it will be added to Crowdin."
+		}
+
+		report += previewSection(markdownCode(cloudName)+" ("+markdownCode(localPath)+")", note, added, changed, removed)
+	}
+
+	// Strings removed from all source files of the string-based project
+	if stringsProject && os.Getenv("PLUGIN_UPLOAD_REMOVED_STRINGS") != "keep" {
+		var removed []stringChange
+		for _, key := range projectKeys {
+			if !allLocalKeys[key] {
+				removed = append(removed, stringChange{key: key, oldText: projectTexts[key]})
+			}
+		}
+
+		fmt.Println("- Preview: removed strings:", len(removed))
+
+		if len(removed) != 0 {
+			total += len(removed)
+			report += previewSection("Removed strings", "", nil, nil, removed)
+		}
+	}
+
+	if total == 0 {
+		report += "\nNo string changes.\n"
+	}
+
+	err = os.WriteFile(outputFile, []byte(report), 0644)
+	if err != nil {
+		exitOnError("failed write preview file:", err.Error())
+	}
+
+	fmt.Println("- Write: ", outputFile)
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+func readManifest(manifestPath string) ([]string, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	var files []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		files = append(files, line)
+	}
+
+	return files, scanner.Err()
+}
+
+func writeManifest(manifestPath string, files []string) error {
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	content := "# Files downloaded from Crowdin by drone-crowdin-v2.\n"
+	content += "# Do not edit, it is used to remove translations that no longer exist.\n"
+	for _, file := range sorted {
+		content += file + "\n"
+	}
+
+	return os.WriteFile(manifestPath, []byte(content), 0644)
+}
+
+// findMatchingFiles returns files in the directory that match one of the patterns.
+// Paths are relative to the directory and use slash as separator.
+func findMatchingFiles(dir string, patterns []string) ([]string, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			// Leftover of an interrupted download
+			if strings.HasPrefix(info.Name(), crowdin.StagingDirPrefix) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		for _, pattern := range patterns {
+			ok, err := path.Match(pattern, rel)
+			if err != nil {
+				return errors.New("bad prune pattern '" + pattern + "': " + err.Error())
+			}
+
+			if ok {
+				files = append(files, rel)
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// removeFile removes the file and its parent directories that became empty.
+func removeFile(dir string, rel string) error {
+	p := filepath.Join(dir, filepath.FromSlash(rel))
+
+	err := os.Remove(p)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for parent := filepath.Dir(p); parent != filepath.Clean(dir); parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindMatchingFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"en.locale",
+		"ru.locale",
+		"docs/README.md",
+		"sub/de.locale",
+		".drone-crowdin-v2-staging-123/files/fr.locale",
+	} {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(filePath), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(filePath, nil, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{"*.locale"}, []string{"en.locale", "ru.locale"}},
+		{[]string{"*/*.locale"}, []string{"sub/de.locale"}},
+		{[]string{"*.locale", "docs/*"}, []string{"docs/README.md", "en.locale", "ru.locale"}},
+		{[]string{"*/*/*.locale"}, nil},
+		{[]string{"*.json"}, nil},
+	}
+
+	for _, test := range tests {
+		got, err := findMatchingFiles(dir, test.patterns)
+		if err != nil {
+			t.Errorf("findMatchingFiles(%q): %s", test.patterns, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("findMatchingFiles(%q) = %q, want %q", test.patterns, got, test.want)
+		}
+	}
+
+	_, err := findMatchingFiles(dir, []string{"["})
+	if err == nil {
+		t.Error("bad pattern: expected error")
+	}
+}
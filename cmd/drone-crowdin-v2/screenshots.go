@@ -0,0 +1,180 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+var screenshotExts = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+// findScreenshots returns images from the directory sorted by name.
+func findScreenshots(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		for _, part := range screenshotExts {
+			if ext == part {
+				names = append(names, entry.Name())
+				break
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+func targetScreenshots(client *crowdin.Client, projectID string) {
+	// Get screenshots parameters
+	screenshotsDir := os.Getenv("PLUGIN_SCREENSHOTS_DIR")
+	if screenshotsDir == "" {
+		exitOnError("empty 'screenshots dir' parameter")
+	}
+
+	autoTag := getBoolVal("PLUGIN_SCREENSHOTS_AUTO_TAG")
+
+	// Format: {"SCREENSHOT_FILE_NAME": ["STRING_KEY", ...]}
+	tags := make(map[string][]string)
+	if tagsFile := os.Getenv("PLUGIN_SCREENSHOTS_TAGS_FILE"); tagsFile != "" {
+		data, err := os.ReadFile(tagsFile)
+		if err != nil {
+			exitOnError("failed read screenshots tags file:", err.Error())
+		}
+
+		err = json.Unmarshal(data, &tags)
+		if err != nil {
+			exitOnError("failed read screenshots tags file:", err.Error())
+		}
+	}
+
+	names, err := findScreenshots(screenshotsDir)
+	if err != nil {
+		exitOnError("failed read screenshots dir:", err.Error())
+	}
+
+	if len(names) == 0 {
+		exitOnError("no screenshots found in", screenshotsDir)
+	}
+
+	for name := range tags {
+		found := false
+		for _, part := range names {
+			if part == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			fmt.Println("WARNING: screenshot '" + name + "' from tags file not found in " + screenshotsDir)
+		}
+	}
+
+	// String IDs by key
+	stringIDs := make(map[string][]string)
+	if len(tags) != 0 {
+		strs, err := client.ListStrings(projectID, "")
+		if err != nil {
+			exitOnError(err)
+		}
+
+		for _, str := range strs {
+			stringIDs[str.Identifier] = append(stringIDs[str.Identifier], str.ID)
+		}
+	}
+
+	// Get screenshots list from Crowdin
+	cloudScreenshots, err := client.ListScreenshots(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	cloudByName := make(map[string]crowdin.Screenshot)
+	for _, part := range cloudScreenshots {
+		cloudByName[part.Name] = part
+	}
+
+	// Add or update screenshots
+	for _, name := range names {
+		localPath := filepath.Join(screenshotsDir, name)
+		keys, hasTags := tags[name]
+
+		cloudScreenshot, exist := cloudByName[name]
+		screenshotID := cloudScreenshot.ID
+
+		if !exist {
+			fmt.Println("- Add:   ", localPath, "->", name)
+			screenshotID, err = client.AddScreenshot(projectID, localPath, name, autoTag && !hasTags)
+			if err != nil {
+				exitOnError(err)
+			}
+
+		} else {
+			fmt.Println("- Update:", localPath, "->", name)
+			err = client.UpdateScreenshot(projectID, screenshotID, localPath, name)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			if autoTag && !hasTags {
+				err = client.AutoTagScreenshot(projectID, screenshotID)
+				if err != nil {
+					exitOnError(err)
+				}
+			}
+		}
+
+		if !hasTags {
+			continue
+		}
+
+		// Tag strings from the tags file
+		var ids []string
+		for _, key := range keys {
+			if len(stringIDs[key]) == 0 {
+				fmt.Println("WARNING: string '" + key + "' for screenshot '" + name + "' not found in Crowdin")
+				continue
+			}
+			ids = append(ids, stringIDs[key]...)
+		}
+
+		fmt.Printf("- Tag:    %s (%d strings)\n", name, len(ids))
+		err = client.SetScreenshotTags(projectID, screenshotID, ids)
+		if err != nil {
+			exitOnError(err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+	"github.com/lcomrade/drone-crowdin-v2/internal/locale"
+)
+
+// checkStringsFormat exits if translations of the source file cannot be written
+// after download, so unsupported files are rejected before anything is changed.
+func checkStringsFormat(localPath string) {
+	format := locale.DetectFormat(localPath)
+	if !locale.CanWrite(format) {
+		exitOnError("file format is not supported by string-based projects:", localPath)
+	}
+}
+
+// uploadStrings syncs source strings of the string-based project with the local source files.
+// It returns IDs of added and changed strings.
+func uploadStrings(client *crowdin.Client, projectID string, targetFiles map[string]uploadFile) []string {
+	removedMode := os.Getenv("PLUGIN_UPLOAD_REMOVED_STRINGS")
+	if removedMode == "" {
+		removedMode = "hide"
+	}
+
+	if removedMode != "hide" && removedMode != "delete" && removedMode != "keep" {
+		exitOnError("bad PLUGIN_UPLOAD_REMOVED_STRINGS parameter value (possible values: hide, delete, keep)")
+	}
+
+	stringContext := getBoolVal("PLUGIN_UPLOAD_STRING_CONTEXT")
+
+	// Read local source strings
+	localPaths := make([]string, 0, len(targetFiles))
+	for localPath := range targetFiles {
+		localPaths = append(localPaths, localPath)
+	}
+	sort.Strings(localPaths)
+
+	for _, localPath := range localPaths {
+		checkStringsFormat(localPath)
+	}
+
+	var entries []locale.Entry
+	keyFile := make(map[string]string)
+	for _, localPath := range localPaths {
+		fileEntries, err := parseLocaleFile(localPath)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		for _, entry := range fileEntries {
+			if other, ok := keyFile[entry.Key]; ok {
+				exitOnError("string key '" + entry.Key + "' is defined in both " + other + " and " + localPath)
+			}
+			keyFile[entry.Key] = localPath
+
+			entries = append(entries, entry)
+		}
+	}
+
+	// Get source strings from Crowdin
+	strs, err := client.ListStrings(projectID, "")
+	if err != nil {
+		exitOnError(err)
+	}
+
+	strsByKey := make(map[string]crowdin.SourceString)
+	for _, str := range strs {
+		strsByKey[str.Identifier] = str
+	}
+
+	// Add or edit strings
+	var changedIDs []string
+	added, changed, removed := 0, 0, 0
+	for _, entry := range entries {
+		context, maxLength := "", 0
+		if stringContext {
+			context, maxLength = parseStringComment(entry.Comment)
+		}
+
+		// Bilingual formats keep the source text separately
+		text := entry.Value
+		if entry.Source != "" {
+			text = entry.Source
+		}
+
+		str, exist := strsByKey[entry.Key]
+		if !exist {
+			fmt.Println("- Add string:   ", entry.Key)
+			stringID, err := client.AddString(projectID, entry.Key, text, context, maxLength)
+			if err != nil {
+				exitOnError(err)
+			}
+			changedIDs = append(changedIDs, stringID)
+			added++
+			continue
+		}
+
+		if str.IsPlural {
+			fmt.Println("WARNING: Crowdin string '" + entry.Key + "' is plural, it is not changed")
+			continue
+		}
+
+		if str.Text != text || str.IsHidden {
+			fmt.Println("- Edit string:  ", entry.Key)
+			err = client.EditStringText(projectID, str.ID, text)
+			if err != nil {
+				exitOnError(err)
+			}
+			changedIDs = append(changedIDs, str.ID)
+			changed++
+		}
+
+		if stringContext {
+			_, err = client.EditString(projectID, str, context, maxLength)
+			if err != nil {
+				exitOnError(err)
+			}
+		}
+	}
+
+	// Hide or delete strings removed from the source files
+	for _, str := range strs {
+		if _, ok := keyFile[str.Identifier]; ok || removedMode == "keep" {
+			continue
+		}
+
+		if removedMode == "delete" {
+			fmt.Println("- Delete string:", str.Identifier)
+			err = client.DeleteString(projectID, str.ID)
+
+		} else {
+			if str.IsHidden {
+				continue
+			}
+
+			fmt.Println("- Hide string:  ", str.Identifier)
+			err = client.SetStringHidden(projectID, str.ID, true)
+		}
+
+		if err != nil {
+			exitOnError(err)
+		}
+		removed++
+	}
+
+	fmt.Printf("Summary: %d added, %d changed, %d removed\n", added, changed, removed)
+
+	return changedIDs
+}
+
+// getStringsFiles returns translation files of the string-based project.
+// Each file has the same keys and format as the local source file.
+func getStringsFiles() []crowdin.StringsFile {
+	var files []crowdin.StringsFile
+	for localPath, file := range getUploadFiles() {
+		checkStringsFormat(localPath)
+
+		entries, err := parseLocaleFile(localPath)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		format := locale.DetectFormat(localPath)
+
+		keys := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			keys = append(keys, entry.Key)
+		}
+
+		files = append(files, crowdin.StringsFile{
+			Name: file.Name,
+			Keys: keys,
+			Write: func(translations map[string]string) ([]byte, error) {
+				var translated []locale.Entry
+				for _, entry := range entries {
+					if val, ok := translations[entry.Key]; ok {
+						translated = append(translated, locale.Entry{Key: entry.Key, Value: val})
+					}
+				}
+
+				return locale.Write(format, translated)
+			},
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+
+	return files
+}
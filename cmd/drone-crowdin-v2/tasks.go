@@ -0,0 +1,154 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+// expandTaskTemplate replaces %DRONE_*% variables and %language% in the task title or description.
+func expandTaskTemplate(s string, languageID string) string {
+	return strings.Replace(expandDroneVars(s), "%language%", languageID, -1)
+}
+
+// parseDeadline accepts a duration from now (for example "168h"), a date or a date with time in RFC 3339 format.
+func parseDeadline(val string) (time.Time, error) {
+	if d, err := time.ParseDuration(val); err == nil {
+		return time.Now().Add(d), nil
+	}
+
+	if t, err := time.Parse("2006-01-02", val); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, val)
+}
+
+// createTasks creates tasks for the files with new or changed strings
+// in the languages that have untranslated strings.
+func createTasks(client *crowdin.Client, projectID string, fileIDs []string) {
+	if len(fileIDs) == 0 {
+		fmt.Println("- Task: no new or changed strings")
+		return
+	}
+
+	// Get task parameters
+	title := os.Getenv("PLUGIN_TASKS_TITLE")
+	if title == "" {
+		title = "New strings from %DRONE_BRANCH% (build %DRONE_BUILD_NUMBER%)"
+	}
+
+	description := os.Getenv("PLUGIN_TASKS_DESCRIPTION")
+	assignees := getListVal("PLUGIN_TASKS_ASSIGNEES")
+	languages := getListVal("PLUGIN_TASKS_LANGUAGES")
+
+	taskTypes := getListVal("PLUGIN_TASKS_TYPE")
+	if len(taskTypes) == 0 {
+		taskTypes = []string{"translate"}
+	}
+
+	for _, taskType := range taskTypes {
+		if taskType != "translate" && taskType != "proofread" {
+			exitOnError("bad PLUGIN_TASKS_TYPE parameter value (possible values: translate, proofread)")
+		}
+	}
+
+	var deadline time.Time
+	if val := os.Getenv("PLUGIN_TASKS_DEADLINE"); val != "" {
+		var err error
+		deadline, err = parseDeadline(val)
+		if err != nil {
+			exitOnError("bad PLUGIN_TASKS_DEADLINE parameter value:", err.Error())
+		}
+	}
+
+	if len(languages) == 0 {
+		project, err := client.GetProject(projectID)
+		if err != nil {
+			exitOnError(err)
+		}
+		languages = project.TargetLanguageIDs
+	}
+	sort.Strings(languages)
+
+	// Get translation progress of the changed files
+	progress := make(map[string]map[string]crowdin.Progress)
+	for _, fileID := range fileIDs {
+		var err error
+		progress[fileID], err = client.FileProgress(projectID, fileID)
+		if err != nil {
+			exitOnError(err)
+		}
+	}
+
+	// Create tasks
+	for _, taskType := range taskTypes {
+		for _, lang := range languages {
+			var fileIDs []string
+			for fileID, fileProgress := range progress {
+				langProgress, ok := fileProgress[lang]
+				if !ok {
+					continue
+				}
+
+				done := langProgress.Translated
+				if taskType == "proofread" {
+					done = langProgress.Approved
+				}
+
+				if done < langProgress.Total {
+					fileIDs = append(fileIDs, fileID)
+				}
+			}
+
+			if len(fileIDs) == 0 {
+				fmt.Println("- Task:", taskType, lang, "(nothing to do, skipped)")
+				continue
+			}
+			sort.Strings(fileIDs)
+
+			opts := crowdin.TaskOptions{
+				Title:       expandTaskTemplate(title, lang),
+				Description: expandTaskTemplate(description, lang),
+				LanguageID:  lang,
+				FileIDs:     fileIDs,
+				Type:        crowdin.TaskTypeTranslate,
+				Deadline:    deadline,
+				Assignees:   assignees,
+			}
+
+			if taskType == "proofread" {
+				opts.Type = crowdin.TaskTypeProofread
+			}
+
+			taskID, err := client.CreateTask(projectID, opts)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			fmt.Println("- Task:", taskType, lang, "->", opts.Title, "(ID: "+taskID+")")
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+func targetTmUpload(client *crowdin.Client, projectID string) {
+	// Get TM parameters
+	tmFile := os.Getenv("PLUGIN_TM_FILE")
+	if tmFile == "" {
+		exitOnError("empty 'TM file' parameter")
+	}
+
+	var scheme map[string]int
+	if schemeStr := os.Getenv("PLUGIN_TM_SCHEME"); schemeStr != "" {
+		err := json.Unmarshal([]byte(schemeStr), &scheme)
+		if err != nil {
+			exitOnError("failed read TM scheme:", err.Error())
+		}
+	}
+
+	firstLineHeader := getBoolVal("PLUGIN_TM_FIRST_LINE_HEADER")
+
+	// Find or create TM
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	name := os.Getenv("PLUGIN_TM_NAME")
+	if name == "" {
+		name = project.Name
+	}
+
+	tmID, err := client.FindTmId(project, name)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	if tmID == "" {
+		tmID, err = client.CreateTm(projectID, name, project.SourceLanguageID)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		fmt.Println("Crowdin TM created:", name, "(ID: "+tmID+")")
+	}
+
+	// Import
+	fmt.Println("- Import:", tmFile, "-> TM", tmID)
+	err = client.ImportTm(tmID, tmFile, scheme, firstLineHeader)
+	if err != nil {
+		exitOnError(err)
+	}
+}
+
+func targetTmDownload(client *crowdin.Client, projectID string) {
+	// Get TM parameters
+	tmFile := os.Getenv("PLUGIN_TM_FILE")
+	if tmFile == "" {
+		exitOnError("empty 'TM file' parameter")
+	}
+
+	sourceLang := os.Getenv("PLUGIN_TM_SOURCE_LANGUAGE")
+	targetLang := os.Getenv("PLUGIN_TM_TARGET_LANGUAGE")
+
+	// Find TM
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	name := os.Getenv("PLUGIN_TM_NAME")
+	if name == "" {
+		name = project.Name
+	}
+
+	tmID, err := client.FindTmId(project, name)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	if tmID == "" {
+		exitOnError("Crowdin TM not found:", name)
+	}
+
+	// Export
+	fmt.Println("- Export: TM", tmID, "->", tmFile)
+	err = client.ExportTm(tmID, tmFile, sourceLang, targetLang)
+	if err != nil {
+		exitOnError(err)
+	}
+}
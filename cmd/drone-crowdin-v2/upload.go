@@ -0,0 +1,354 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+)
+
+type uploadFile struct {
+	Name string `json:"name"`
+	crowdin.FileOptions
+}
+
+func (file *uploadFile) UnmarshalJSON(data []byte) error {
+	// Short format: "CROWDIN_FILE_NAME"
+	var name string
+	if json.Unmarshal(data, &name) == nil {
+		file.Name = name
+		return nil
+	}
+
+	// Full format: {"name": "CROWDIN_FILE_NAME", "type": "ini", ...}
+	type uploadFileFull uploadFile
+	return json.Unmarshal(data, (*uploadFileFull)(file))
+}
+
+func getUploadFiles() map[string]uploadFile {
+	// Get files list from parameters
+	filesList := os.Getenv("PLUGIN_UPLOAD_FILES")
+
+	if filesList == "" {
+		exitOnError("upload files list cannot be empty")
+	}
+
+	targetFiles := make(map[string]uploadFile)
+	err := json.Unmarshal([]byte(filesList), &targetFiles)
+	if err != nil {
+		exitOnError("failed read upload files list:", err.Error())
+	}
+
+	if len(filesList) == 0 {
+		exitOnError("upload files list cannot be empty")
+	}
+
+	cloudBadSymbols := string(crowdin.BadSymbols)
+	for localPath, file := range targetFiles {
+		if localPath == "" {
+			exitOnError("local file path cannot be empty")
+		}
+
+		if file.Name == "" {
+			exitOnError("Crowdin file name cannot be empty")
+		}
+
+		if strings.ContainsAny(file.Name, cloudBadSymbols) {
+			exitOnError("Crowdin file name cannot contain '"+cloudBadSymbols+"':", file.Name)
+		}
+	}
+
+	return targetFiles
+}
+
+func targetUpload(client *crowdin.Client, projectID string) {
+	targetFiles := getUploadFiles()
+	cloudBadSymbols := string(crowdin.BadSymbols)
+
+	// String-based projects have no files
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	if project.Type == crowdin.ProjectTypeStrings {
+		// These options work with Crowdin files
+		if os.Getenv("PLUGIN_UPLOAD_RENAME_FILES") != "" {
+			exitOnError("PLUGIN_UPLOAD_RENAME_FILES is not supported for string-based projects")
+		}
+
+		for _, name := range []string{"PLUGIN_UPLOAD_DELETE_OBSOLETE", "PLUGIN_UPLOAD_PRETRANSLATE", "PLUGIN_UPLOAD_TASKS"} {
+			if getBoolVal(name) {
+				exitOnError(name, "is not supported for string-based projects")
+			}
+		}
+
+		if getBoolVal("PLUGIN_UPLOAD_LINT") {
+			lintUpload(client, projectID, targetFiles, nil)
+		}
+
+		changed := uploadStrings(client, projectID, targetFiles)
+
+		if labels := getUploadLabels(); len(labels) != 0 {
+			labelStrings(client, projectID, labels, changed)
+		}
+		return
+	}
+
+	// Get rename list from parameters
+	renameFiles := make(map[string]string)
+	if renameList := os.Getenv("PLUGIN_UPLOAD_RENAME_FILES"); renameList != "" {
+		err := json.Unmarshal([]byte(renameList), &renameFiles)
+		if err != nil {
+			exitOnError("failed read rename files list:", err.Error())
+		}
+	}
+
+	for oldName, newName := range renameFiles {
+		if oldName == "" || newName == "" {
+			exitOnError("Crowdin file name cannot be empty")
+		}
+
+		if strings.ContainsAny(newName, cloudBadSymbols) {
+			exitOnError("Crowdin file name cannot contain '"+cloudBadSymbols+"':", newName)
+		}
+
+		found := false
+		for _, file := range targetFiles {
+			if file.Name == newName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			exitOnError("new Crowdin file name is not in upload files list:", newName)
+		}
+	}
+
+	// Rename files before update, so translations stay attached to them
+	for oldName, newName := range renameFiles {
+		oldID, err := client.FindFileId(projectID, oldName)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if oldID == "" {
+			fmt.Println("- Rename:", oldName, "->", newName, "(not found, skipped)")
+			continue
+		}
+
+		newID, err := client.FindFileId(projectID, newName)
+		if err != nil {
+			exitOnError(err)
+		}
+
+		if newID != "" {
+			exitOnError("failed rename '" + oldName + "': Crowdin file '" + newName + "' already exists")
+		}
+
+		fmt.Println("- Rename:", oldName, "->", newName)
+		err = client.RenameFile(projectID, oldID, newName)
+		if err != nil {
+			exitOnError(err)
+		}
+	}
+
+	// Get files list from Crowdin
+	cloudFiles, err := client.ListFiles(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	cloudFilesByName := make(map[string]crowdin.File)
+	for _, cloudFile := range cloudFiles {
+		cloudFilesByName[cloudFile.Name] = cloudFile
+	}
+
+	// Check source files
+	if getBoolVal("PLUGIN_UPLOAD_LINT") {
+		lintUpload(client, projectID, targetFiles, cloudFilesByName)
+	}
+
+	stringContext := getBoolVal("PLUGIN_UPLOAD_STRING_CONTEXT")
+	labels := getUploadLabels()
+	tasks := getBoolVal("PLUGIN_UPLOAD_TASKS")
+
+	// Labels and tasks only need new and changed strings
+	trackChanges := len(labels) != 0 || tasks
+
+	// Add or update files
+	var changedStringIDs, changedFileIDs []string
+	for localPath, file := range targetFiles {
+		cloudFile, exist := cloudFilesByName[file.Name]
+
+		// Remember strings to find changed ones
+		var before []crowdin.SourceString
+		if exist && trackChanges {
+			before, err = client.ListStrings(projectID, cloudFile.ID)
+			if err != nil {
+				exitOnError(err)
+			}
+		}
+
+		// Add if not exist
+		if !exist {
+			fmt.Println("- Add:   ", localPath, "->", file.Name)
+			err = client.AddFile(projectID, localPath, file.Name, file.FileOptions)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			// Else update file
+		} else {
+			fmt.Println("- Update:", localPath, "->", file.Name)
+			err = client.UpdateFile(projectID, localPath, file.Name, cloudFile.ID, file.FileOptions)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			// Apply changed file settings
+			changed, err := client.EditFile(projectID, cloudFile, file.FileOptions)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			if changed {
+				fmt.Println("- Edit:  ", file.Name)
+			}
+
+			if file.Type != "" && file.Type != cloudFile.Type {
+				fmt.Println("WARNING: Crowdin file '" + file.Name + "' has type '" + cloudFile.Type + "', file type can only be set when the file is added")
+			}
+		}
+
+		// Set context and max length of strings from source file comments
+		if stringContext {
+			applyStringContext(client, projectID, localPath, file.Name)
+		}
+
+		// Find new and changed strings
+		if trackChanges {
+			fileID, err := client.FindFileId(projectID, file.Name)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			after, err := client.ListStrings(projectID, fileID)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			changed := changedStrings(before, after)
+			if len(changed) != 0 {
+				changedStringIDs = append(changedStringIDs, changed...)
+				changedFileIDs = append(changedFileIDs, fileID)
+			}
+		}
+	}
+
+	// Label new and changed strings
+	if len(labels) != 0 {
+		labelStrings(client, projectID, labels, changedStringIDs)
+	}
+
+	// Delete files that are not in the upload list
+	if getBoolVal("PLUGIN_UPLOAD_DELETE_OBSOLETE") {
+		deleteObsoleteFiles(client, projectID, targetFiles)
+	}
+
+	// Pre-translate uploaded files
+	if getBoolVal("PLUGIN_UPLOAD_PRETRANSLATE") {
+		targetPreTranslate(client, projectID)
+	}
+
+	// Create tasks for translators
+	if tasks {
+		createTasks(client, projectID, changedFileIDs)
+	}
+}
+
+func deleteObsoleteFiles(client *crowdin.Client, projectID string, targetFiles map[string]uploadFile) {
+	dryRun := getBoolVal("PLUGIN_UPLOAD_DELETE_OBSOLETE_DRY_RUN")
+	protected := getListVal("PLUGIN_UPLOAD_DELETE_OBSOLETE_PROTECT")
+
+	for _, pattern := range protected {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			exitOnError("bad protected file pattern '"+pattern+"':", err.Error())
+		}
+	}
+
+	isProtected := func(file crowdin.File) bool {
+		for _, pattern := range protected {
+			okName, _ := path.Match(pattern, file.Name)
+			okPath, _ := path.Match(pattern, strings.TrimPrefix(file.Path, "/"))
+			if okName || okPath {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// Files that should stay in Crowdin.
+	// The plugin uploads files to the project root, so they are matched by full path.
+	keep := make(map[string]bool)
+	for _, file := range targetFiles {
+		keep["/"+file.Name] = true
+	}
+
+	// Get files list from Crowdin
+	files, err := client.ListFiles(projectID)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	for _, file := range files {
+		// Files in branches and directories are not managed by the plugin
+		if file.BranchID != 0 || file.DirectoryID != 0 {
+			continue
+		}
+
+		if keep[file.Path] {
+			continue
+		}
+
+		if isProtected(file) {
+			fmt.Println("- Keep:  ", file.Path, "(protected)")
+			continue
+		}
+
+		if dryRun {
+			fmt.Println("- Obsolete:", file.Path, "(dry run)")
+			continue
+		}
+
+		fmt.Println("- Delete:", file.Path)
+		err = client.DeleteFile(projectID, file.ID)
+		if err != nil {
+			exitOnError(err)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lcomrade/drone-crowdin-v2/internal/crowdin"
+	"github.com/lcomrade/drone-crowdin-v2/internal/locale"
+)
+
+const (
+	checkOff  = ""
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+func getCheckVal(envName string) string {
+	val := os.Getenv(envName)
+	if val != checkOff && val != checkWarn && val != checkFail {
+		exitOnError("bad " + envName + " parameter value (possible values: warn, fail)")
+	}
+
+	return val
+}
+
+// reportProblems prints problems and returns an error if they must fail the step.
+func reportProblems(mode string, title string, problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+
+	prefix := "WARNING: "
+	if mode == checkFail {
+		prefix = "ERROR: "
+	}
+
+	for _, problem := range problems {
+		fmt.Println(prefix + problem)
+	}
+
+	if mode == checkFail {
+		return errors.New(title + ": " + strconv.Itoa(len(problems)) + " problem(s) found")
+	}
+
+	return nil
+}
+
+// validateFiles parses extracted files of known formats.
+func validateFiles(mode string, dir string, files []crowdin.ExtractedFile) error {
+	var problems []string
+
+	for _, file := range files {
+		format := locale.DetectFormat(file.Path)
+		if format == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			return err
+		}
+
+		_, err = locale.Parse(format, data)
+		if err != nil {
+			problems = append(problems, file.Path+": "+err.Error())
+		}
+	}
+
+	return reportProblems(mode, "translation files validation", problems)
+}
+
+func parseLocaleFile(filePath string) ([]locale.Entry, error) {
+	format := locale.DetectFormat(filePath)
+	if format == "" {
+		return nil, errors.New("unknown file format: " + filePath)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := locale.Parse(format, data)
+	if err != nil {
+		return nil, errors.New(filePath + ": " + err.Error())
+	}
+
+	return entries, nil
+}
+
+// hasSourceText returns true if the entries are from a bilingual file.
+func hasSourceText(entries []locale.Entry) bool {
+	for _, entry := range entries {
+		if entry.Source != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkPlaceholders compares placeholders of the translated strings with the source strings.
+// Sources map Crowdin file names to local source file paths.
+func checkPlaceholders(mode string, dir string, files []crowdin.ExtractedFile, sources map[string]string) error {
+	var problems []string
+
+	sourceStrings := make(map[string]map[string]string)
+	getSourceStrings := func(file crowdin.ExtractedFile) (map[string]string, error) {
+		localPath, ok := sources[file.OriginalPath]
+		if !ok {
+			localPath, ok = sources[path.Base(file.OriginalPath)]
+		}
+
+		if !ok {
+			return nil, nil
+		}
+
+		if strs, ok := sourceStrings[localPath]; ok {
+			return strs, nil
+		}
+
+		entries, err := parseLocaleFile(localPath)
+		if err != nil {
+			return nil, err
+		}
+
+		strs := make(map[string]string)
+		for _, entry := range entries {
+			strs[entry.Key] = entry.Value
+		}
+		sourceStrings[localPath] = strs
+
+		return strs, nil
+	}
+
+	for _, file := range files {
+		if locale.DetectFormat(file.Path) == "" {
+			continue
+		}
+
+		entries, err := parseLocaleFile(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			fmt.Println("WARNING: placeholders check: file skipped:", err.Error())
+			continue
+		}
+
+		source, err := getSourceStrings(file)
+		if err != nil {
+			return err
+		}
+
+		// Monolingual files can only be checked against the source file
+		if source == nil && len(sources) != 0 && !hasSourceText(entries) {
+			fmt.Println("WARNING: placeholders check: source file not found in upload files list, file skipped:", file.Path)
+			continue
+		}
+
+		for _, entry := range entries {
+			if entry.Value == "" {
+				continue
+			}
+
+			// Bilingual formats contain the source text
+			sourceText := entry.Source
+			if sourceText == "" {
+				sourceText = source[entry.Key]
+			}
+
+			if sourceText == "" {
+				continue
+			}
+
+			missing, extra := locale.ComparePlaceholders(sourceText, entry.Value)
+			if len(missing) == 0 && len(extra) == 0 {
+				continue
+			}
+
+			problem := file.Language + ": " + file.Path + ": line " + strconv.Itoa(entry.Line) + ": " + entry.Key + ":"
+			if len(missing) != 0 {
+				problem += " missing " + strings.Join(missing, " ")
+			}
+			if len(extra) != 0 {
+				problem += " unexpected " + strings.Join(extra, " ")
+			}
+
+			problems = append(problems, problem)
+		}
+	}
+
+	return reportProblems(mode, "placeholders check", problems)
+}
@@ -0,0 +1,176 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// cache keeps project IDs and file lists between runs.
+// Data of different API tokens is stored separately, keyed by token hash.
+type cache struct {
+	path  string
+	ttl   time.Duration
+	token string
+	all   map[string]*cacheData
+}
+
+type cacheData struct {
+	Projects map[string]cachedProject `json:"projects"`
+	Files    map[string]cachedFiles   `json:"files"`
+}
+
+type cachedProject struct {
+	ID   string    `json:"id"`
+	Time time.Time `json:"time"`
+}
+
+type cachedFiles struct {
+	Files []File    `json:"files"`
+	Time  time.Time `json:"time"`
+}
+
+func (client *Client) SetCache(path string, ttl time.Duration) error {
+	hash := sha256.Sum256([]byte(client.key))
+
+	c := &cache{
+		path:  path,
+		ttl:   ttl,
+		token: hex.EncodeToString(hash[:]),
+		all:   make(map[string]*cacheData),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.New("failed read cache: " + err.Error())
+	}
+
+	if len(data) != 0 {
+		// Broken cache is ignored
+		if json.Unmarshal(data, &c.all) != nil {
+			c.all = make(map[string]*cacheData)
+		}
+	}
+
+	if c.all[c.token] == nil {
+		c.all[c.token] = &cacheData{}
+	}
+
+	if c.all[c.token].Projects == nil {
+		c.all[c.token].Projects = make(map[string]cachedProject)
+	}
+
+	if c.all[c.token].Files == nil {
+		c.all[c.token].Files = make(map[string]cachedFiles)
+	}
+
+	client.cache = c
+	return nil
+}
+
+func (c *cache) data() *cacheData {
+	return c.all[c.token]
+}
+
+func (c *cache) fresh(t time.Time) bool {
+	return time.Since(t) < c.ttl
+}
+
+func (c *cache) save() {
+	// Remove expired records
+	for _, data := range c.all {
+		for name, project := range data.Projects {
+			if !c.fresh(project.Time) {
+				delete(data.Projects, name)
+			}
+		}
+
+		for projectID, files := range data.Files {
+			if !c.fresh(files.Time) {
+				delete(data.Files, projectID)
+			}
+		}
+	}
+
+	data, err := json.Marshal(c.all)
+	if err != nil {
+		return
+	}
+
+	// Cache is optional, so write errors are ignored
+	os.WriteFile(c.path, data, 0600)
+}
+
+func (c *cache) getProjectID(name string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+
+	project, ok := c.data().Projects[name]
+	if !ok || !c.fresh(project.Time) {
+		return "", false
+	}
+
+	return project.ID, true
+}
+
+func (c *cache) setProjectID(name string, projectID string) {
+	if c == nil {
+		return
+	}
+
+	c.data().Projects[name] = cachedProject{ID: projectID, Time: time.Now()}
+	c.save()
+}
+
+func (c *cache) getFiles(projectID string) ([]File, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	files, ok := c.data().Files[projectID]
+	if !ok || !c.fresh(files.Time) {
+		return nil, false
+	}
+
+	return files.Files, true
+}
+
+func (c *cache) setFiles(projectID string, files []File) {
+	if c == nil {
+		return
+	}
+
+	c.data().Files[projectID] = cachedFiles{Files: files, Time: time.Now()}
+	c.save()
+}
+
+func (c *cache) invalidateFiles(projectID string) {
+	if c == nil {
+		return
+	}
+
+	delete(c.data().Files, projectID)
+	c.save()
+}
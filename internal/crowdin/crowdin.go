@@ -37,9 +37,17 @@ const (
 
 var BadSymbols = []rune{'\\', '/', ':', '*', '?', '"', '<', '>', '|'}
 
+// Read more: https://developer.crowdin.com/api/v2/#section/Introduction/Patch-Requests
+type patchReq struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
 type Client struct {
 	key    string
 	client *http.Client
+	cache  *cache
 }
 
 func NewClient(key string) *Client {
@@ -51,6 +59,36 @@ func NewClient(key string) *Client {
 	}
 }
 
+func containsString(list []string, s string) bool {
+	for _, part := range list {
+		if part == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func equalStringSets(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[string]int)
+	for _, part := range a {
+		set[part]++
+	}
+
+	for _, part := range b {
+		if set[part] == 0 {
+			return false
+		}
+		set[part]--
+	}
+
+	return true
+}
+
 func readBody(r io.Reader) string {
 	if r == nil {
 		return "<nil>"
@@ -81,6 +119,26 @@ func (client *Client) get(s string, goodCode int) (*http.Response, error) {
 	return resp, nil
 }
 
+func (client *Client) delete(s string, goodCode int) (*http.Response, error) {
+	req, err := http.NewRequest("DELETE", baseAddr+s, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Authorization", "Bearer "+client.key)
+
+	resp, err := client.client.Do(req)
+	if err != nil {
+		return nil, errors.New("crowdin api: " + err.Error())
+	}
+
+	if resp.StatusCode != goodCode {
+		return nil, errors.New("crowdin api: DELETE " + s + ": " + resp.Status + ": " + readBody(resp.Body))
+	}
+
+	return resp, nil
+}
+
 func (client *Client) dlToTmpFile(u string) (string, error) {
 	// Request
 	req, err := http.NewRequest("GET", u, nil)
@@ -0,0 +1,420 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.translations.builds.post
+type buildProjectReq struct {
+	BranchID                        int64    `json:"branchId,omitempty"`
+	TargetLanguageIds               []string `json:"targetLanguageIds,omitempty"`
+	SkipUntranslatedStrings         bool     `json:"skipUntranslatedStrings,omitempty"`
+	SkipUntranslatedFiles           bool     `json:"skipUntranslatedFiles,omitempty"`
+	ExportApprovedOnly              bool     `json:"exportApprovedOnly,omitempty"`
+	ExportWithMinApprovalsCount     int      `json:"exportWithMinApprovalsCount,omitempty"`
+	ExportStringsThatPassedWorkflow bool     `json:"exportStringsThatPassedWorkflow,omitempty"`
+	LabelIds                        []int64  `json:"labelIds,omitempty"`
+	ExcludeLabelIds                 []int64  `json:"excludeLabelIds,omitempty"`
+}
+
+type buildProjectResp struct {
+	Data struct {
+		ID       int    `json:"id"`
+		Status   string `json:"status"`
+		Progress int    `json:"progress"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.translations.builds.getMany
+type listBuildsResp struct {
+	Data []struct {
+		Data struct {
+			ID         int             `json:"id"`
+			Status     string          `json:"status"`
+			CreatedAt  time.Time       `json:"createdAt"`
+			Attributes buildAttributes `json:"attributes"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// buildAttributes are the options the build was made with.
+// DirectoryID is set for builds of a single directory.
+type buildAttributes struct {
+	buildProjectReq
+	DirectoryID int64 `json:"directoryId"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.bundles.getMany
+type listBundlesResp struct {
+	Data []struct {
+		Data struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// CheckBuildOptions returns an error if the build options cannot be used together.
+func (opts DownloadOptions) CheckBuildOptions() error {
+	switch {
+	case opts.SkipUntranslatedStrings && opts.SkipUntranslatedFiles:
+		return errors.New("skip untranslated strings and skip untranslated files cannot be used together")
+
+	case len(opts.SkipUntranslatedStringsLanguages) != 0 && opts.SkipUntranslatedFiles:
+		return errors.New("skip untranslated strings languages and skip untranslated files cannot be used together")
+
+	case len(opts.SkipUntranslatedStringsLanguages) != 0 && opts.SkipUntranslatedStrings:
+		return errors.New("skip untranslated strings languages cannot be used when untranslated strings are skipped for all languages")
+
+	case opts.ExportWithMinApprovalsCount < 0:
+		return errors.New("export with min approvals count cannot be negative")
+
+	case opts.ExportApprovedOnly && opts.ExportWithMinApprovalsCount != 0:
+		return errors.New("export approved only and export with min approvals count cannot be used together")
+
+	case opts.ExportStringsThatPassedWorkflow && (opts.ExportApprovedOnly || opts.ExportWithMinApprovalsCount != 0):
+		return errors.New("export strings that passed workflow cannot be used with approval filters")
+	}
+
+	for _, id := range opts.LabelIDs {
+		if containsString(opts.ExcludeLabelIDs, id) {
+			return errors.New("label cannot be included and excluded at the same time: " + id)
+		}
+	}
+
+	if opts.BundleID != "" {
+		if opts.SkipUntranslatedStrings || opts.SkipUntranslatedFiles || opts.ExportApprovedOnly ||
+			len(opts.SkipUntranslatedStringsLanguages) != 0 || opts.ExportWithMinApprovalsCount != 0 ||
+			opts.ExportStringsThatPassedWorkflow || len(opts.LabelIDs) != 0 || len(opts.ExcludeLabelIDs) != 0 {
+			return errors.New("build options cannot be used with bundle, set them in the bundle settings")
+		}
+	}
+
+	return nil
+}
+
+// BuildConfig returns the effective build options, one per line.
+func (opts DownloadOptions) BuildConfig() string {
+	if opts.BundleID != "" {
+		return "bundle: " + opts.BundleID + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString("skip untranslated strings: " + strconv.FormatBool(opts.SkipUntranslatedStrings))
+	if len(opts.SkipUntranslatedStringsLanguages) != 0 {
+		b.WriteString(" (true for " + strings.Join(opts.SkipUntranslatedStringsLanguages, ", ") + ")")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("skip untranslated files: " + strconv.FormatBool(opts.SkipUntranslatedFiles) + "\n")
+	b.WriteString("export approved only: " + strconv.FormatBool(opts.ExportApprovedOnly) + "\n")
+	b.WriteString("export with min approvals count: " + strconv.Itoa(opts.ExportWithMinApprovalsCount) + "\n")
+	b.WriteString("export strings that passed workflow: " + strconv.FormatBool(opts.ExportStringsThatPassedWorkflow) + "\n")
+
+	if len(opts.LabelIDs) != 0 {
+		b.WriteString("label IDs: " + strings.Join(opts.LabelIDs, ", ") + "\n")
+	}
+
+	if len(opts.ExcludeLabelIDs) != 0 {
+		b.WriteString("exclude label IDs: " + strings.Join(opts.ExcludeLabelIDs, ", ") + "\n")
+	}
+
+	b.WriteString("force rebuild: " + strconv.FormatBool(opts.ForceRebuild) + "\n")
+
+	return b.String()
+}
+
+func parseIDs(ids []string, what string) ([]int64, error) {
+	var result []int64
+	for _, id := range ids {
+		val, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return nil, errors.New("crowdin api: bad " + what + " ID: " + id)
+		}
+		result = append(result, val)
+	}
+
+	return result, nil
+}
+
+// buildRequests returns build requests for the options.
+// Languages from SkipUntranslatedStringsLanguages are built separately.
+func (client *Client) buildRequests(projectID string, opts DownloadOptions) ([]buildProjectReq, error) {
+	labelIDs, err := parseIDs(opts.LabelIDs, "label")
+	if err != nil {
+		return nil, err
+	}
+
+	excludeLabelIDs, err := parseIDs(opts.ExcludeLabelIDs, "label")
+	if err != nil {
+		return nil, err
+	}
+
+	buildReq := buildProjectReq{
+		SkipUntranslatedStrings:         opts.SkipUntranslatedStrings,
+		SkipUntranslatedFiles:           opts.SkipUntranslatedFiles,
+		ExportApprovedOnly:              opts.ExportApprovedOnly,
+		ExportWithMinApprovalsCount:     opts.ExportWithMinApprovalsCount,
+		ExportStringsThatPassedWorkflow: opts.ExportStringsThatPassedWorkflow,
+		LabelIds:                        labelIDs,
+		ExcludeLabelIds:                 excludeLabelIDs,
+	}
+
+	if len(opts.SkipUntranslatedStringsLanguages) == 0 {
+		return []buildProjectReq{buildReq}, nil
+	}
+
+	// Split target languages
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool)
+	for _, lang := range opts.SkipUntranslatedStringsLanguages {
+		if !containsString(project.TargetLanguageIDs, lang) {
+			return nil, errors.New("language is not a target language of the project: " + lang)
+		}
+		skip[lang] = true
+	}
+
+	var skipLangs, otherLangs []string
+	for _, lang := range project.TargetLanguageIDs {
+		if skip[lang] {
+			skipLangs = append(skipLangs, lang)
+		} else {
+			otherLangs = append(otherLangs, lang)
+		}
+	}
+
+	var buildReqs []buildProjectReq
+	if len(skipLangs) != 0 {
+		skipReq := buildReq
+		skipReq.TargetLanguageIds = skipLangs
+		skipReq.SkipUntranslatedStrings = true
+		buildReqs = append(buildReqs, skipReq)
+	}
+
+	if len(otherLangs) != 0 {
+		otherReq := buildReq
+		otherReq.TargetLanguageIds = otherLangs
+		buildReqs = append(buildReqs, otherReq)
+	}
+
+	return buildReqs, nil
+}
+
+func equalIDSets(a []int64, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[int64]bool)
+	for _, id := range a {
+		set[id] = true
+	}
+
+	for _, id := range b {
+		if !set[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sameBuild returns true if the build was made with the same options as the request.
+// Empty target languages mean all project target languages.
+// Builds of a single directory never match, because they do not contain all files.
+func sameBuild(attrs buildAttributes, buildReq buildProjectReq, projectLangs []string) bool {
+	if attrs.DirectoryID != 0 {
+		return false
+	}
+
+	reqLangs := buildReq.TargetLanguageIds
+	if len(reqLangs) == 0 {
+		reqLangs = projectLangs
+	}
+
+	attrsLangs := attrs.TargetLanguageIds
+	if len(attrsLangs) == 0 {
+		attrsLangs = projectLangs
+	}
+
+	return attrs.BranchID == buildReq.BranchID &&
+		equalStringSets(attrsLangs, reqLangs) &&
+		attrs.SkipUntranslatedStrings == buildReq.SkipUntranslatedStrings &&
+		attrs.SkipUntranslatedFiles == buildReq.SkipUntranslatedFiles &&
+		attrs.ExportApprovedOnly == buildReq.ExportApprovedOnly &&
+		attrs.ExportWithMinApprovalsCount == buildReq.ExportWithMinApprovalsCount &&
+		attrs.ExportStringsThatPassedWorkflow == buildReq.ExportStringsThatPassedWorkflow &&
+		equalIDSets(attrs.LabelIds, buildReq.LabelIds) &&
+		equalIDSets(attrs.ExcludeLabelIds, buildReq.ExcludeLabelIds)
+}
+
+// findBuild returns the newest finished or running build with the same options,
+// started after the last activity in the project. It returns an empty ID if there is no such build.
+func (client *Client) findBuild(project *Project, buildReq buildProjectReq) (string, string, error) {
+	if project.LastActivity.IsZero() {
+		return "", "", nil
+	}
+
+	s := "/api/v2/projects/" + project.ID + "/translations/builds"
+
+	var found string
+	var foundStatus string
+	var foundTime time.Time
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get(s+"?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+		if err != nil {
+			return "", "", err
+		}
+		defer resp.Body.Close()
+
+		var data listBuildsResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return "", "", errors.New("crowdin api: GET " + s + ": failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			build := part.Data
+			if build.Status != "finished" && build.Status != "inProgress" {
+				continue
+			}
+
+			if !build.CreatedAt.After(project.LastActivity) || !build.CreatedAt.After(foundTime) {
+				continue
+			}
+
+			if !sameBuild(build.Attributes, buildReq, project.TargetLanguageIDs) {
+				continue
+			}
+
+			found = strconv.Itoa(build.ID)
+			foundStatus = build.Status
+			foundTime = build.CreatedAt
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return found, foundStatus, nil
+}
+
+// build builds project translations and returns the path to request the archive download link.
+// If the project is not nil, an existing up-to-date build with the same options is reused.
+func (client *Client) build(projectID string, buildReq buildProjectReq, project *Project) (string, error) {
+	if project != nil {
+		buildID, status, err := client.findBuild(project, buildReq)
+		if err != nil {
+			return "", err
+		}
+
+		if buildID != "" {
+			if status != "finished" {
+				err = client.waitOperation("/api/v2/projects/" + projectID + "/translations/builds/" + buildID)
+				if err != nil {
+					return "", err
+				}
+			}
+
+			return "/api/v2/projects/" + projectID + "/translations/builds/" + buildID + "/download", nil
+		}
+	}
+
+	// Start build Crowdin project
+	respBuild, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/translations/builds", 201, buildReq)
+	if err != nil {
+		return "", err
+	}
+	defer respBuild.Body.Close()
+
+	var dataBuild buildProjectResp
+	err = json.NewDecoder(respBuild.Body).Decode(&dataBuild)
+	if err != nil {
+		return "", errors.New("crowdin api: POST /api/v2/projects/" + projectID + "/translations/builds: failed decode JSON: " + err.Error())
+	}
+
+	buildID := strconv.Itoa(dataBuild.Data.ID)
+
+	// Wait until build is finished
+	err = client.waitOperation("/api/v2/projects/" + projectID + "/translations/builds/" + buildID)
+	if err != nil {
+		return "", err
+	}
+
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.translations.builds.download.download
+	return "/api/v2/projects/" + projectID + "/translations/builds/" + buildID + "/download", nil
+}
+
+// exportBundle exports the bundle and returns the path to request the archive download link.
+func (client *Client) exportBundle(projectID string, bundleID string) (string, error) {
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.bundles.exports.post
+	exportID, err := client.startOperation("/api/v2/projects/"+projectID+"/bundles/"+bundleID+"/exports", 202, struct{}{})
+	if err != nil {
+		return "", err
+	}
+
+	// Wait until export is finished
+	err = client.waitOperation("/api/v2/projects/" + projectID + "/bundles/" + bundleID + "/exports/" + exportID)
+	if err != nil {
+		return "", err
+	}
+
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.bundles.exports.download.download
+	return "/api/v2/projects/" + projectID + "/bundles/" + bundleID + "/exports/" + exportID + "/download", nil
+}
+
+// FindBundleId returns an empty string if the bundle is not found.
+func (client *Client) FindBundleId(projectID string, name string) (string, error) {
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/projects/"+projectID+"/bundles?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		var data listBundlesResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return "", errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/bundles: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			if part.Data.Name == name {
+				return strconv.FormatInt(part.Data.ID, 10), nil
+			}
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSameBuild(t *testing.T) {
+	projectLangs := []string{"de", "ru"}
+
+	tests := []struct {
+		name  string
+		attrs string
+		req   buildProjectReq
+		want  bool
+	}{
+		{
+			name:  "same options",
+			attrs: `{"targetLanguageIds":["ru","de"],"skipUntranslatedStrings":true}`,
+			req:   buildProjectReq{SkipUntranslatedStrings: true},
+			want:  true,
+		},
+		{
+			name:  "languages in other order",
+			attrs: `{"targetLanguageIds":["ru","de"]}`,
+			req:   buildProjectReq{TargetLanguageIds: []string{"de", "ru"}},
+			want:  true,
+		},
+		{
+			name:  "other languages",
+			attrs: `{"targetLanguageIds":["ru"]}`,
+			req:   buildProjectReq{},
+			want:  false,
+		},
+		{
+			name:  "other export options",
+			attrs: `{"exportApprovedOnly":true}`,
+			req:   buildProjectReq{},
+			want:  false,
+		},
+		{
+			name:  "same labels",
+			attrs: `{"labelIds":[2,1]}`,
+			req:   buildProjectReq{LabelIds: []int64{1, 2}},
+			want:  true,
+		},
+		{
+			name:  "branch build",
+			attrs: `{"branchId":12}`,
+			req:   buildProjectReq{},
+			want:  false,
+		},
+		{
+			name:  "same branch",
+			attrs: `{"branchId":12}`,
+			req:   buildProjectReq{BranchID: 12},
+			want:  true,
+		},
+		{
+			name:  "directory build",
+			attrs: `{"directoryId":4}`,
+			req:   buildProjectReq{},
+			want:  false,
+		},
+	}
+
+	for _, test := range tests {
+		var attrs buildAttributes
+		err := json.Unmarshal([]byte(test.attrs), &attrs)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+
+		got := sameBuild(attrs, test.req, projectLangs)
+		if got != test.want {
+			t.Errorf("%s: sameBuild() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCheckBuildOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  DownloadOptions
+		valid bool
+	}{
+		{"defaults", DownloadOptions{}, true},
+		{"skip untranslated strings", DownloadOptions{SkipUntranslatedStrings: true, ExportApprovedOnly: true}, true},
+		{"skip untranslated strings languages", DownloadOptions{SkipUntranslatedStringsLanguages: []string{"ru"}}, true},
+		{"min approvals", DownloadOptions{ExportWithMinApprovalsCount: 2}, true},
+		{"labels", DownloadOptions{LabelIDs: []string{"1", "2"}, ExcludeLabelIDs: []string{"3"}}, true},
+		{"bundle", DownloadOptions{BundleID: "5"}, true},
+		{"skip strings and files", DownloadOptions{SkipUntranslatedStrings: true, SkipUntranslatedFiles: true}, false},
+		{"skip languages and files", DownloadOptions{SkipUntranslatedStringsLanguages: []string{"ru"}, SkipUntranslatedFiles: true}, false},
+		{"skip languages and all strings", DownloadOptions{SkipUntranslatedStringsLanguages: []string{"ru"}, SkipUntranslatedStrings: true}, false},
+		{"negative min approvals", DownloadOptions{ExportWithMinApprovalsCount: -1}, false},
+		{"approved only and min approvals", DownloadOptions{ExportApprovedOnly: true, ExportWithMinApprovalsCount: 2}, false},
+		{"workflow and approved only", DownloadOptions{ExportStringsThatPassedWorkflow: true, ExportApprovedOnly: true}, false},
+		{"label included and excluded", DownloadOptions{LabelIDs: []string{"1", "2"}, ExcludeLabelIDs: []string{"2"}}, false},
+		{"bundle with build options", DownloadOptions{BundleID: "5", SkipUntranslatedFiles: true}, false},
+		{"bundle with labels", DownloadOptions{BundleID: "5", LabelIDs: []string{"1"}}, false},
+	}
+
+	for _, test := range tests {
+		err := test.opts.CheckBuildOptions()
+		if (err == nil) != test.valid {
+			t.Errorf("%s: CheckBuildOptions() = %v, want valid %v", test.name, err, test.valid)
+		}
+	}
+}
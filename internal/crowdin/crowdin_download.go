@@ -19,94 +19,359 @@
 package crowdin
 
 import (
-	"encoding/json"
 	"errors"
-	"net/http"
 	"os"
-	"strconv"
-	"time"
+	"path/filepath"
+	"strings"
 )
 
-// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.translations.builds.post
-type buildProjectReq struct {
-	BranchID                int64    `json:"branchId,omitempty"`
-	TargetLanguageIds       []string `json:"targetLanguageIds,omitempty"`
-	SkipUntranslatedStrings bool     `json:"skipUntranslatedStrings"`
-	SkipUntranslatedFiles   bool     `json:"skipUntranslatedFiles"`
-	ExportApprovedOnly      bool     `json:"exportApprovedOnly"`
-}
+type DownloadOptions struct {
+	SkipUntranslatedStrings bool
+	SkipUntranslatedFiles   bool
+	ExportApprovedOnly      bool
+
+	// SkipUntranslatedStringsLanguages enables SkipUntranslatedStrings only for these languages.
+	// A separate build is made for them.
+	SkipUntranslatedStringsLanguages []string
+
+	ExportWithMinApprovalsCount     int
+	ExportStringsThatPassedWorkflow bool
+
+	// LabelIDs limits the download to strings with at least one of the labels.
+	// Strings with one of ExcludeLabelIDs are not downloaded.
+	LabelIDs        []string
+	ExcludeLabelIDs []string
+
+	// ForceRebuild starts a new build even if there is an up-to-date build with the same options.
+	ForceRebuild bool
+
+	// BundleID exports the bundle instead of building the project translations.
+	// Export options are set in the bundle, so the build options above cannot be used with it.
+	BundleID string
+
+	// PathTemplate is the destination path of every extracted file,
+	// for example "%two_letters_code%/%original_file_name%".
+	// If empty, the archive is extracted as is.
+	PathTemplate string
+
+	// PathMapping overrides PathTemplate for individual files.
+	// The key is the file path inside the language directory of the archive.
+	PathMapping map[string]string
 
-type buildProjectResp struct {
-	Data struct {
-		ID       int    `json:"id"`
-		Status   string `json:"status"`
-		Progress int    `json:"progress"`
-	} `json:"data"`
+	// LanguageMapping overrides language codes in file and directory names.
+	// If UseProjectLanguageMapping is set, it is merged with the project language mapping.
+	LanguageMapping           LanguageMapping
+	UseProjectLanguageMapping bool
+
+	// Validate is called after the archive is extracted to the staging directory.
+	// If it returns an error, the destination directory is left untouched.
+	Validate func(dir string, files []ExtractedFile) error
 }
 
-// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.translations.builds.download.download
-type downloadProjectResp struct {
-	Data struct {
-		URL string `json:"url"`
-		//ExpireIn time.Time `json:"expireIn"`
-	} `json:"data"`
+const (
+	StatusNew       = "new"
+	StatusChanged   = "changed"
+	StatusUnchanged = "unchanged"
+)
+
+type ExtractedFile struct {
+	Name         string // Archive entry name
+	Path         string // Path relative to the destination directory
+	Language     string // Crowdin language ID, empty if unknown
+	OriginalPath string // Path inside the language directory of the archive
+	Status       string // StatusNew, StatusChanged or StatusUnchanged
 }
 
-func (client *Client) Download(destDir string, projectID string, skipUntranslatedStrings bool, skipUntranslatedFiles bool, exportApprovedOnly bool) ([]string, error) {
-	// Start build Crowdin project
-	buildReq := buildProjectReq{
-		SkipUntranslatedStrings: skipUntranslatedStrings,
-		SkipUntranslatedFiles:   skipUntranslatedFiles,
-		ExportApprovedOnly:      exportApprovedOnly,
+func (client *Client) pathResolver(projectID string, opts DownloadOptions) (func(name string) (ExtractedFile, error), error) {
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := opts.LanguageMapping
+	if opts.UseProjectLanguageMapping {
+		mapping = project.LanguageMapping.merge(mapping)
 	}
 
-	respBuild, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/translations/builds", 201, buildReq)
+	return func(name string) (ExtractedFile, error) {
+		entryPath, err := safeRelPath(name)
+		if err != nil {
+			return ExtractedFile{}, err
+		}
+
+		file := ExtractedFile{
+			Name: name,
+			Path: entryPath,
+		}
+
+		lang, langDirPlaceholder, originalPath, ok := detectLanguage(entryPath, project.TargetLanguages, mapping)
+		if ok {
+			file.Language = lang.ID
+			file.OriginalPath = originalPath
+		}
+		values := lang.placeholders(mapping)
+
+		template, ok := opts.PathMapping[originalPath]
+		if !ok {
+			template = opts.PathTemplate
+		}
+
+		if template == "" {
+			// Rename language directory
+			if file.Language != "" {
+				file.Path = values[langDirPlaceholder] + "/" + originalPath
+			}
+
+			return file, nil
+		}
+
+		if file.Language == "" {
+			return ExtractedFile{}, errors.New("failed detect language of archive entry: " + name)
+		}
+
+		file.Path, err = safeRelPath(strings.TrimLeft(expandPathTemplate(template, values, originalPath), "/"))
+		if err != nil {
+			return ExtractedFile{}, errors.New("archive entry " + name + ": " + err.Error())
+		}
+
+		return file, nil
+	}, nil
+}
+
+func (client *Client) Download(destDir string, projectID string, opts DownloadOptions) ([]ExtractedFile, error) {
+	err := opts.CheckBuildOptions()
 	if err != nil {
 		return nil, err
 	}
-	defer respBuild.Body.Close()
 
-	var dataBuild buildProjectResp
-	err = json.NewDecoder(respBuild.Body).Decode(&dataBuild)
+	// Prepare destination paths of the archive entries
+	resolve, err := client.pathResolver(projectID, opts)
 	if err != nil {
-		return nil, errors.New("crowdin api: POST /api/v2/projects/" + projectID + "/translations/builds: failed decode JSON: " + err.Error())
+		return nil, err
 	}
 
-	buildID := strconv.Itoa(dataBuild.Data.ID)
-
-	// Download build when it finished
-	for i := 0; i < 6; i++ {
-		// Wait
-		time.Sleep(5 * time.Second)
-
-		// Check build status
-		var respDl *http.Response
-		respDl, err = client.get("/api/v2/projects/"+projectID+"/translations/builds/"+buildID+"/download", 200)
+	// Build translations
+	var archives []string
+	if opts.BundleID != "" {
+		archive, err := client.exportBundle(projectID, opts.BundleID)
 		if err != nil {
-			continue
+			return nil, err
 		}
-		defer respDl.Body.Close()
+		archives = append(archives, archive)
 
-		var dataDl downloadProjectResp
-		err = json.NewDecoder(respDl.Body).Decode(&dataDl)
+	} else {
+		buildReqs, err := client.buildRequests(projectID, opts)
 		if err != nil {
-			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/translations/builds/" + buildID + "/download: failed decode JSON: " + err.Error())
+			return nil, err
+		}
+
+		// Project is required to check existing builds
+		var project *Project
+		if !opts.ForceRebuild {
+			project, err = client.GetProject(projectID)
+			if err != nil {
+				return nil, err
+			}
 		}
 
+		for _, buildReq := range buildReqs {
+			archive, err := client.build(projectID, buildReq, project)
+			if err != nil {
+				return nil, err
+			}
+			archives = append(archives, archive)
+		}
+	}
+
+	// Extract zip archives to the staging directory
+	st, err := newStaging(destDir)
+	if err != nil {
+		return nil, errors.New("crowdin api: failed create staging directory: " + err.Error())
+	}
+	defer st.remove()
+
+	var extracted []ExtractedFile
+	byPath := make(map[string]string)
+	for _, archive := range archives {
 		// Request zip archive from server
-		tmpFile, err := client.dlToTmpFile(dataDl.Data.URL)
+		dlURL, err := client.downloadURL(archive)
+		if err != nil {
+			return nil, err
+		}
+
+		tmpFile, err := client.dlToTmpFile(dlURL)
 		if err != nil {
 			return nil, err
 		}
 		defer os.Remove(tmpFile)
 
-		// Extract zip archive
-		extracted, err := unzip(tmpFile, destDir)
+		files, err := unzip(tmpFile, st.filesDir(), resolve)
 		if err != nil {
 			return nil, errors.New("crowdin api: failed extract archive: " + err.Error())
 		}
-		return extracted, nil
+
+		for _, file := range files {
+			if other, ok := byPath[file.Path]; ok {
+				return nil, errors.New("archive entries '" + other + "' and '" + file.Name + "' are extracted to the same path: " + file.Path)
+			}
+			byPath[file.Path] = file.Name
+		}
+
+		extracted = append(extracted, files...)
+	}
+
+	return finishDownload(st, extracted, opts)
+}
+
+// finishDownload validates files in the staging directory and moves them into place.
+func finishDownload(st *staging, extracted []ExtractedFile, opts DownloadOptions) ([]ExtractedFile, error) {
+	// Check extracted files
+	if opts.Validate != nil {
+		err := opts.Validate(st.filesDir(), extracted)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Move extracted files into place
+	err := st.commit(extracted)
+	if err != nil {
+		return nil, errors.New("crowdin api: failed move extracted files: " + err.Error())
+	}
+
+	return extracted, nil
+}
+
+func hasAnyLabel(str SourceString, labelIDs []string) bool {
+	for _, id := range str.LabelIDs {
+		if containsString(labelIDs, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StringsFile is a translation file of the string-based project.
+type StringsFile struct {
+	// Name is the file path inside the language directory,
+	// it is resolved like archive entries of the file-based projects.
+	Name string
+
+	// Keys are source string identifiers in the file order.
+	Keys []string
+
+	// Write formats translations by string identifier.
+	Write func(translations map[string]string) ([]byte, error)
+}
+
+// DownloadStrings writes translations of the string-based project to the files.
+// Untranslated strings get the source text, unless SkipUntranslatedStrings is set.
+func (client *Client) DownloadStrings(destDir string, projectID string, files []StringsFile, opts DownloadOptions) ([]ExtractedFile, error) {
+	if opts.BundleID != "" || opts.ExportStringsThatPassedWorkflow || opts.ExportWithMinApprovalsCount > 1 {
+		return nil, errors.New("bundles, workflow and min approvals count filters are not supported for string-based projects")
+	}
+
+	err := opts.CheckBuildOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare destination paths of the files
+	resolve, err := client.pathResolver(projectID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := client.GetProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	skipUntranslated := make(map[string]bool)
+	for _, lang := range opts.SkipUntranslatedStringsLanguages {
+		skipUntranslated[lang] = true
+	}
+
+	strs, err := client.ListStrings(projectID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	strsByKey := make(map[string]SourceString)
+	for _, str := range strs {
+		if (len(opts.LabelIDs) == 0 || hasAnyLabel(str, opts.LabelIDs)) && !hasAnyLabel(str, opts.ExcludeLabelIDs) {
+			strsByKey[str.Identifier] = str
+		}
+	}
+
+	// Write files to the staging directory
+	st, err := newStaging(destDir)
+	if err != nil {
+		return nil, errors.New("crowdin api: failed create staging directory: " + err.Error())
+	}
+	defer st.remove()
+
+	var extracted []ExtractedFile
+	byPath := make(map[string]string)
+	for _, lang := range project.TargetLanguages {
+		approvedOnly := opts.ExportApprovedOnly || opts.ExportWithMinApprovalsCount == 1
+		translations, err := client.ListTranslations(projectID, lang.ID, approvedOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			values := make(map[string]string)
+			translated := 0
+			for _, key := range file.Keys {
+				str, ok := strsByKey[key]
+				if !ok {
+					continue
+				}
+
+				if text, ok := translations[str.ID]; ok {
+					values[key] = text
+					translated++
+				} else if !opts.SkipUntranslatedStrings && !skipUntranslated[lang.ID] {
+					values[key] = str.Text
+				}
+			}
+
+			if translated == 0 && opts.SkipUntranslatedFiles {
+				continue
+			}
+
+			name := lang.ID + "/" + file.Name
+			part, err := resolve(name)
+			if err != nil {
+				return nil, err
+			}
+
+			if other, ok := byPath[part.Path]; ok {
+				return nil, errors.New("files '" + other + "' and '" + name + "' are written to the same path: " + part.Path)
+			}
+			byPath[part.Path] = name
+
+			data, err := file.Write(values)
+			if err != nil {
+				return nil, errors.New(name + ": " + err.Error())
+			}
+
+			filePath := filepath.Join(st.filesDir(), filepath.FromSlash(part.Path))
+			err = os.MkdirAll(filepath.Dir(filePath), 0755)
+			if err != nil {
+				return nil, err
+			}
+
+			err = os.WriteFile(filePath, data, 0644)
+			if err != nil {
+				return nil, err
+			}
+
+			extracted = append(extracted, part)
+		}
 	}
 
-	return nil, err
+	return finishDownload(st, extracted, opts)
 }
@@ -0,0 +1,183 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var glossaryFormats = []string{"tbx", "csv", "xlsx"}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.glossaries.getMany
+type listGlossariesResp struct {
+	Data []struct {
+		Data struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			LanguageID string `json:"languageId"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.glossaries.post
+type addGlossaryReq struct {
+	Name       string `json:"name"`
+	LanguageID string `json:"languageId"`
+}
+
+type addGlossaryResp struct {
+	Data struct {
+		ID int64 `json:"id"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.glossaries.imports.post
+type importGlossaryReq struct {
+	StorageID               int64          `json:"storageId"`
+	Scheme                  map[string]int `json:"scheme,omitempty"`
+	FirstLineContainsHeader bool           `json:"firstLineContainsHeader,omitempty"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.glossaries.exports.post
+type exportGlossaryReq struct {
+	Format string `json:"format"`
+}
+
+// fileFormat returns the file format by file extension if it is one of the formats.
+func fileFormat(filePath string, formats ...string) (string, error) {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), ".")
+	for _, part := range formats {
+		if format == part {
+			return format, nil
+		}
+	}
+
+	return "", errors.New("unsupported file format (possible formats: " + strings.Join(formats, ", ") + "): " + filePath)
+}
+
+// FindOrCreateGlossary returns the ID of the glossary assigned to the project and true if the glossary has been created.
+// Glossaries of other projects are ignored. The new glossary is assigned to the project.
+func (client *Client) FindOrCreateGlossary(project *Project, name string) (string, bool, error) {
+	// Find
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/glossaries?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+		if err != nil {
+			return "", false, err
+		}
+		defer resp.Body.Close()
+
+		var data listGlossariesResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return "", false, errors.New("crowdin api: GET /api/v2/glossaries: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			glossaryID := strconv.FormatInt(part.Data.ID, 10)
+			if part.Data.Name == name && containsString(project.AssignedGlossaryIDs, glossaryID) {
+				return glossaryID, false, nil
+			}
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	// Create
+	resp, err := client.sendJSON("POST", "/api/v2/glossaries", 201, addGlossaryReq{Name: name, LanguageID: project.SourceLanguageID})
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	var data addGlossaryResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", false, errors.New("crowdin api: POST /api/v2/glossaries: failed decode JSON: " + err.Error())
+	}
+
+	// Assign to the project
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.patch
+	editReq := []patchReq{
+		{Op: "add", Path: "/assignedGlossaries/-", Value: data.Data.ID},
+	}
+
+	respEdit, err := client.sendJSON("PATCH", "/api/v2/projects/"+project.ID, 200, editReq)
+	if err != nil {
+		return "", false, err
+	}
+	defer respEdit.Body.Close()
+
+	return strconv.FormatInt(data.Data.ID, 10), true, nil
+}
+
+func (client *Client) ImportGlossary(glossaryID string, localPath string, scheme map[string]int, firstLineContainsHeader bool) error {
+	_, err := fileFormat(localPath, glossaryFormats...)
+	if err != nil {
+		return err
+	}
+
+	// Add file to Crowdin cloud storage
+	storageID, err := client.uploadToCloudStorage(localPath, filepath.Base(localPath))
+	if err != nil {
+		return err
+	}
+
+	// Start import
+	importReq := importGlossaryReq{
+		StorageID:               storageID,
+		Scheme:                  scheme,
+		FirstLineContainsHeader: firstLineContainsHeader,
+	}
+
+	importID, err := client.startOperation("/api/v2/glossaries/"+glossaryID+"/imports", 202, importReq)
+	if err != nil {
+		return err
+	}
+
+	// Wait until import is finished
+	return client.waitOperation("/api/v2/glossaries/" + glossaryID + "/imports/" + importID)
+}
+
+func (client *Client) ExportGlossary(glossaryID string, destPath string) error {
+	format, err := fileFormat(destPath, glossaryFormats...)
+	if err != nil {
+		return err
+	}
+
+	// Start export
+	exportID, err := client.startOperation("/api/v2/glossaries/"+glossaryID+"/exports", 202, exportGlossaryReq{Format: format})
+	if err != nil {
+		return err
+	}
+
+	// Wait until export is finished
+	err = client.waitOperation("/api/v2/glossaries/" + glossaryID + "/exports/" + exportID)
+	if err != nil {
+		return err
+	}
+
+	// Download
+	return client.dlToFile("/api/v2/glossaries/"+glossaryID+"/exports/"+exportID+"/download", destPath)
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.labels.getMany
+type listLabelsResp struct {
+	Data []struct {
+		Data struct {
+			ID    int64  `json:"id"`
+			Title string `json:"title"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.labels.post
+type addLabelReq struct {
+	Title string `json:"title"`
+}
+
+type addLabelResp struct {
+	Data struct {
+		ID int64 `json:"id"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.labels.strings.post
+type assignLabelReq struct {
+	StringIDs []int64 `json:"stringIds"`
+}
+
+// ListLabels returns label IDs by title.
+func (client *Client) ListLabels(projectID string) (map[string]string, error) {
+	labels := make(map[string]string)
+
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/projects/"+projectID+"/labels?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var data listLabelsResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/labels: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			labels[part.Data.Title] = strconv.FormatInt(part.Data.ID, 10)
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return labels, nil
+}
+
+func (client *Client) AddLabel(projectID string, title string) (string, error) {
+	resp, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/labels", 201, addLabelReq{Title: title})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data addLabelResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: POST /api/v2/projects/" + projectID + "/labels: failed decode JSON: " + err.Error())
+	}
+
+	return strconv.FormatInt(data.Data.ID, 10), nil
+}
+
+// AssignLabel assigns the label to the strings.
+func (client *Client) AssignLabel(projectID string, labelID string, stringIDs []string) error {
+	// Strings are sent in batches to keep request size small
+	for start := 0; start < len(stringIDs); start += paginationLimit {
+		end := start + paginationLimit
+		if end > len(stringIDs) {
+			end = len(stringIDs)
+		}
+
+		var assignReq assignLabelReq
+		for _, id := range stringIDs[start:end] {
+			stringID, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return errors.New("crowdin api: bad string ID: " + id)
+			}
+			assignReq.StringIDs = append(assignReq.StringIDs, stringID)
+		}
+
+		resp, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/labels/"+labelID+"/strings", 200, assignReq)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+
+	return nil
+}
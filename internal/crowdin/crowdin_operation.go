@@ -0,0 +1,169 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	pollAttempts = 60
+)
+
+// Status of asynchronous operations: translation builds, imports, exports, pre-translations.
+// Read more: https://developer.crowdin.com/api/v2/#section/Introduction/Asynchronous-Operations
+type operationResp struct {
+	Data struct {
+		Identifier string `json:"identifier"`
+		Status     string `json:"status"`
+		Progress   int    `json:"progress"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.glossaries.exports.download.download
+type downloadURLResp struct {
+	Data struct {
+		URL string `json:"url"`
+		//ExpireIn time.Time `json:"expireIn"`
+	} `json:"data"`
+}
+
+// startOperation starts an asynchronous operation and returns its identifier.
+func (client *Client) startOperation(s string, goodCode int, body interface{}) (string, error) {
+	resp, err := client.sendJSON("POST", s, goodCode, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data operationResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: POST " + s + ": failed decode JSON: " + err.Error())
+	}
+
+	if data.Data.Identifier == "" {
+		return "", errors.New("crowdin api: POST " + s + ": empty operation identifier")
+	}
+
+	return data.Data.Identifier, nil
+}
+
+// waitOperation polls the operation status until it is finished.
+func (client *Client) waitOperation(s string) error {
+	var err error
+
+	for i := 0; i < pollAttempts; i++ {
+		// Wait
+		time.Sleep(pollInterval)
+
+		// Check status
+		var status string
+		status, err = client.operationStatus(s)
+		if err != nil {
+			continue
+		}
+
+		switch status {
+		case "finished":
+			return nil
+		case "failed", "canceled":
+			return errors.New("crowdin api: GET " + s + ": operation " + status)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return errors.New("crowdin api: GET " + s + ": operation is not finished in time")
+}
+
+func (client *Client) operationStatus(s string) (string, error) {
+	resp, err := client.get(s, 200)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data operationResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: GET " + s + ": failed decode JSON: " + err.Error())
+	}
+
+	return data.Data.Status, nil
+}
+
+// downloadURL returns the link to download the operation result.
+func (client *Client) downloadURL(s string) (string, error) {
+	resp, err := client.get(s, 200)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data downloadURLResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: GET " + s + ": failed decode JSON: " + err.Error())
+	}
+
+	return data.Data.URL, nil
+}
+
+// dlToFile downloads the operation result to the file.
+func (client *Client) dlToFile(s string, destPath string) error {
+	u, err := client.downloadURL(s)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := client.dlToTmpFile(u)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile)
+
+	src, err := os.Open(tmpFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	err = os.MkdirAll(filepath.Dir(destPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	_, err = io.Copy(dest, src)
+	return err
+}
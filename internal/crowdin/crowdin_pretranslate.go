@@ -0,0 +1,137 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.pre-translations.post
+type preTranslateReq struct {
+	LanguageIDs                   []string `json:"languageIds"`
+	FileIDs                       []int64  `json:"fileIds"`
+	Method                        string   `json:"method,omitempty"`
+	EngineID                      int64    `json:"engineId,omitempty"`
+	AutoApproveOption             string   `json:"autoApproveOption,omitempty"`
+	DuplicateTranslations         bool     `json:"duplicateTranslations,omitempty"`
+	TranslateUntranslatedOnly     bool     `json:"translateUntranslatedOnly"`
+	TranslateWithPerfectMatchOnly bool     `json:"translateWithPerfectMatchOnly,omitempty"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.pre-translations.reports.getReport
+type preTranslateReportResp struct {
+	Data struct {
+		Languages []struct {
+			ID    string `json:"id"`
+			Files []struct {
+				ID         string `json:"id"`
+				Statistics struct {
+					Phrases int `json:"phrases"`
+					Words   int `json:"words"`
+				} `json:"statistics"`
+			} `json:"files"`
+		} `json:"languages"`
+	} `json:"data"`
+}
+
+type PreTranslateOptions struct {
+	LanguageIDs []string
+	FileIDs     []string
+
+	// Method is "tm" or "mt". EngineID is required for "mt".
+	Method   string
+	EngineID string
+
+	// AutoApproveOption is "none", "all", "exceptAutoSubstituted" or "perfectMatchOnly".
+	AutoApproveOption             string
+	DuplicateTranslations         bool
+	TranslateUntranslatedOnly     bool
+	TranslateWithPerfectMatchOnly bool
+}
+
+// PreTranslate starts pre-translation and waits until it is finished.
+// It returns the pre-translation identifier.
+func (client *Client) PreTranslate(projectID string, opts PreTranslateOptions) (string, error) {
+	preReq := preTranslateReq{
+		LanguageIDs:                   opts.LanguageIDs,
+		Method:                        opts.Method,
+		AutoApproveOption:             opts.AutoApproveOption,
+		DuplicateTranslations:         opts.DuplicateTranslations,
+		TranslateUntranslatedOnly:     opts.TranslateUntranslatedOnly,
+		TranslateWithPerfectMatchOnly: opts.TranslateWithPerfectMatchOnly,
+	}
+
+	for _, id := range opts.FileIDs {
+		fileID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return "", errors.New("crowdin api: bad file ID: " + id)
+		}
+		preReq.FileIDs = append(preReq.FileIDs, fileID)
+	}
+
+	if opts.EngineID != "" {
+		engineID, err := strconv.ParseInt(opts.EngineID, 10, 64)
+		if err != nil {
+			return "", errors.New("crowdin api: bad MT engine ID: " + opts.EngineID)
+		}
+		preReq.EngineID = engineID
+	}
+
+	// Start pre-translation
+	preID, err := client.startOperation("/api/v2/projects/"+projectID+"/pre-translations", 202, preReq)
+	if err != nil {
+		return "", err
+	}
+
+	// Wait until pre-translation is finished
+	err = client.waitOperation("/api/v2/projects/" + projectID + "/pre-translations/" + preID)
+	if err != nil {
+		return "", err
+	}
+
+	return preID, nil
+}
+
+// PreTranslateReport returns the number of pre-translated strings by language ID.
+func (client *Client) PreTranslateReport(projectID string, preTranslationID string) (map[string]int, error) {
+	s := "/api/v2/projects/" + projectID + "/pre-translations/" + preTranslationID + "/report"
+
+	resp, err := client.get(s, 200)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data preTranslateReportResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return nil, errors.New("crowdin api: GET " + s + ": failed decode JSON: " + err.Error())
+	}
+
+	report := make(map[string]int)
+	for _, lang := range data.Data.Languages {
+		for _, file := range lang.Files {
+			report[lang.ID] += file.Statistics.Phrases
+		}
+	}
+
+	return report, nil
+}
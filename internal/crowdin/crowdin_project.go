@@ -21,7 +21,10 @@ package crowdin
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.getMany
@@ -32,7 +35,8 @@ type projectsListResp struct {
 			SourceLanguageID  string   `json:"sourceLanguageId"`
 			TargetLanguageIds []string `json:"targetLanguageIds"`
 			Name              string   `json:"name"`
-			//CName string `json:"cname"`
+			Identifier        string   `json:"identifier"`
+			CName             string   `json:"cname"`
 		} `json:"data"`
 	} `json:"data"`
 	Pagination struct {
@@ -41,6 +45,48 @@ type projectsListResp struct {
 	} `json:"pagination"`
 }
 
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.post
+type addProjectReq struct {
+	Name              string   `json:"name"`
+	SourceLanguageID  string   `json:"sourceLanguageId"`
+	TargetLanguageIds []string `json:"targetLanguageIds,omitempty"`
+	Visibility        string   `json:"visibility,omitempty"`
+	QACheckIsActive   *bool    `json:"qaCheckIsActive,omitempty"`
+	UseGlobalTM       *bool    `json:"useGlobalTm,omitempty"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.get
+type projectResp struct {
+	Data struct {
+		ID                int64          `json:"id"`
+		Name              string         `json:"name"`
+		Identifier        string         `json:"identifier"`
+		Type              int            `json:"type"`
+		SourceLanguageID  string         `json:"sourceLanguageId"`
+		TargetLanguageIds []string       `json:"targetLanguageIds"`
+		TargetLanguages   []languageData `json:"targetLanguages"`
+		LastActivity      time.Time      `json:"lastActivity"`
+
+		AssignedGlossaries []int64 `json:"assignedGlossaries"`
+
+		// Crowdin returns an empty array instead of an empty object
+		LanguageMapping json.RawMessage `json:"languageMapping"`
+		AssignedTms     json.RawMessage `json:"assignedTms"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.languages.get
+type languageData struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	TwoLettersCode   string `json:"twoLettersCode"`
+	ThreeLettersCode string `json:"threeLettersCode"`
+	Locale           string `json:"locale"`
+	AndroidCode      string `json:"androidCode"`
+	OsxCode          string `json:"osxCode"`
+	OsxLocale        string `json:"osxLocale"`
+}
+
 // Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.files.getMany
 type listFilesResp struct {
 	Data []struct {
@@ -54,6 +100,8 @@ type listFilesResp struct {
 			Type        string `json:"type"`
 			Path        string `json:"path"`
 			Status      string `json:"status"`
+
+			ExcludedTargetLanguages []string `json:"excludedTargetLanguages"`
 		} `json:"data"`
 	} `json:"data"`
 	Pagination struct {
@@ -62,8 +110,15 @@ type listFilesResp struct {
 	} `json:"pagination"`
 }
 
+// FindProjectIdByName finds the project by its name. If there is no project with exactly
+// the same name, the name is compared case-insensitively with project names and identifiers.
 func (client *Client) FindProjectIdByName(name string) (string, error) {
-	for offset := 0; ; offset++ {
+	if projectID, ok := client.cache.getProjectID(name); ok {
+		return projectID, nil
+	}
+
+	var exact, similar []string
+	for offset := 0; ; offset += paginationLimit {
 		resp, err := client.get("/api/v2/projects?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
 		if err != nil {
 			return "", err
@@ -76,42 +131,239 @@ func (client *Client) FindProjectIdByName(name string) (string, error) {
 			return "", errors.New("crowdin api: GET /api/v2/projects: failed decode JSON: " + err.Error())
 		}
 
-		if len(data.Data) == 0 {
+		for _, part := range data.Data {
+			projectID := strconv.FormatInt(part.Data.ID, 10)
+
+			switch {
+			case part.Data.Name == name:
+				exact = append(exact, projectID)
+			case strings.EqualFold(part.Data.Name, name),
+				part.Data.Identifier != "" && strings.EqualFold(part.Data.Identifier, name),
+				part.Data.CName != "" && strings.EqualFold(part.Data.CName, name):
+				similar = append(similar, projectID)
+			}
+		}
+
+		if len(data.Data) < paginationLimit {
 			break
 		}
+	}
 
-		for _, part := range data.Data {
-			if part.Data.Name == name {
-				return strconv.FormatInt(part.Data.ID, 10), nil
-			}
+	matches := exact
+	if len(matches) == 0 {
+		matches = similar
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		client.cache.setProjectID(name, matches[0])
+		return matches[0], nil
+	}
+
+	return "", errors.New("crowdin api: project name is ambiguous: " + name + " (matching project IDs: " + strings.Join(matches, ", ") + ")")
+}
+
+type ProjectOptions struct {
+	Name              string
+	SourceLanguageID  string
+	TargetLanguageIDs []string
+	Visibility        string
+	QACheckIsActive   *bool
+	UseGlobalTM       *bool
+}
+
+func (client *Client) CreateProject(opts ProjectOptions) (string, error) {
+	createReq := addProjectReq{
+		Name:              opts.Name,
+		SourceLanguageID:  opts.SourceLanguageID,
+		TargetLanguageIds: opts.TargetLanguageIDs,
+		Visibility:        opts.Visibility,
+		QACheckIsActive:   opts.QACheckIsActive,
+		UseGlobalTM:       opts.UseGlobalTM,
+	}
+
+	resp, err := client.sendJSON("POST", "/api/v2/projects", 201, createReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data projectResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: POST /api/v2/projects: failed decode JSON: " + err.Error())
+	}
+
+	return strconv.FormatInt(data.Data.ID, 10), nil
+}
+
+const (
+	ProjectTypeFiles   = 0
+	ProjectTypeStrings = 1
+)
+
+type Project struct {
+	ID                string
+	Name              string
+	Identifier        string
+	Type              int // ProjectTypeFiles or ProjectTypeStrings
+	SourceLanguageID  string
+	TargetLanguageIDs []string
+	TargetLanguages   []Language
+	LanguageMapping   LanguageMapping
+	LastActivity      time.Time
+
+	// Glossaries and translation memories used by the project
+	AssignedGlossaryIDs []string
+	AssignedTmIDs       []string
+}
+
+type Language struct {
+	ID               string
+	Name             string
+	TwoLettersCode   string
+	ThreeLettersCode string
+	Locale           string
+	AndroidCode      string
+	OsxCode          string
+	OsxLocale        string
+}
+
+func (client *Client) GetProject(projectID string) (*Project, error) {
+	resp, err := client.get("/api/v2/projects/"+projectID, 200)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var data projectResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + ": failed decode JSON: " + err.Error())
+	}
+
+	project := Project{
+		ID:                strconv.FormatInt(data.Data.ID, 10),
+		Name:              data.Data.Name,
+		Identifier:        data.Data.Identifier,
+		Type:              data.Data.Type,
+		SourceLanguageID:  data.Data.SourceLanguageID,
+		TargetLanguageIDs: data.Data.TargetLanguageIds,
+		LastActivity:      data.Data.LastActivity,
+	}
+
+	for _, lang := range data.Data.TargetLanguages {
+		project.TargetLanguages = append(project.TargetLanguages, Language(lang))
+	}
+
+	for _, glossaryID := range data.Data.AssignedGlossaries {
+		project.AssignedGlossaryIDs = append(project.AssignedGlossaryIDs, strconv.FormatInt(glossaryID, 10))
+	}
+
+	if len(data.Data.AssignedTms) != 0 && data.Data.AssignedTms[0] == '{' {
+		var assignedTms map[string]json.RawMessage
+		err = json.Unmarshal(data.Data.AssignedTms, &assignedTms)
+		if err != nil {
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + ": failed decode assigned TMs: " + err.Error())
+		}
+
+		for tmID := range assignedTms {
+			project.AssignedTmIDs = append(project.AssignedTmIDs, tmID)
+		}
+		sort.Strings(project.AssignedTmIDs)
+	}
+
+	if len(data.Data.LanguageMapping) != 0 && data.Data.LanguageMapping[0] == '{' {
+		err = json.Unmarshal(data.Data.LanguageMapping, &project.LanguageMapping)
+		if err != nil {
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + ": failed decode language mapping: " + err.Error())
 		}
 	}
 
-	return "", errors.New("crowdin api: project name not found: " + name)
+	return &project, nil
 }
 
-func (client *Client) FindFileId(projectID string, fileName string) (string, error) {
-	for offset := 0; ; offset++ {
+func (client *Client) SetTargetLanguages(projectID string, languageIDs []string) error {
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.patch
+	editReq := []patchReq{
+		{Op: "replace", Path: "/targetLanguageIds", Value: languageIDs},
+	}
+
+	resp, err := client.sendJSON("PATCH", "/api/v2/projects/"+projectID, 200, editReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+type File struct {
+	ID          string `json:"id"`
+	BranchID    int64  `json:"branchId"`
+	DirectoryID int64  `json:"directoryId"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+
+	ExcludedTargetLanguages []string `json:"excludedTargetLanguages"`
+}
+
+func (client *Client) ListFiles(projectID string) ([]File, error) {
+	if files, ok := client.cache.getFiles(projectID); ok {
+		return files, nil
+	}
+
+	var files []File
+
+	for offset := 0; ; offset += paginationLimit {
 		resp, err := client.get("/api/v2/projects/"+projectID+"/files?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		defer resp.Body.Close()
 
 		var data listFilesResp
 		err = json.NewDecoder(resp.Body).Decode(&data)
 		if err != nil {
-			return "", errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/files: failed decode JSON: " + err.Error())
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/files: failed decode JSON: " + err.Error())
 		}
 
-		if len(data.Data) == 0 {
+		for _, part := range data.Data {
+			files = append(files, File{
+				ID:          strconv.FormatInt(part.Data.ID, 10),
+				BranchID:    part.Data.BranchID,
+				DirectoryID: part.Data.DirectoryID,
+				Name:        part.Data.Name,
+				Path:        part.Data.Path,
+				Title:       part.Data.Title,
+				Type:        part.Data.Type,
+
+				ExcludedTargetLanguages: part.Data.ExcludedTargetLanguages,
+			})
+		}
+
+		if len(data.Data) < paginationLimit {
 			break
 		}
+	}
 
-		for _, part := range data.Data {
-			if part.Data.Name == fileName {
-				return strconv.FormatInt(part.Data.ID, 10), nil
-			}
+	client.cache.setFiles(projectID, files)
+	return files, nil
+}
+
+func (client *Client) FindFileId(projectID string, fileName string) (string, error) {
+	files, err := client.ListFiles(projectID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		if file.Name == fileName {
+			return file.ID, nil
 		}
 	}
 
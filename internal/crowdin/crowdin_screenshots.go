@@ -0,0 +1,184 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.screenshots.getMany
+type listScreenshotsResp struct {
+	Data []struct {
+		Data struct {
+			ID        int64  `json:"id"`
+			Name      string `json:"name"`
+			TagsCount int    `json:"tagsCount"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.screenshots.post
+type addScreenshotReq struct {
+	StorageID int64  `json:"storageId"`
+	Name      string `json:"name"`
+	AutoTag   bool   `json:"autoTag,omitempty"`
+}
+
+type addScreenshotResp struct {
+	Data struct {
+		ID int64 `json:"id"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.screenshots.put
+type updateScreenshotReq struct {
+	StorageID int64  `json:"storageId"`
+	Name      string `json:"name"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.screenshots.tags.putMany
+type addTagReq struct {
+	StringID int64 `json:"stringId"`
+}
+
+type autoTagReq struct {
+	AutoTag bool `json:"autoTag"`
+}
+
+type Screenshot struct {
+	ID        string
+	Name      string
+	TagsCount int
+}
+
+func (client *Client) ListScreenshots(projectID string) ([]Screenshot, error) {
+	var screenshots []Screenshot
+
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/projects/"+projectID+"/screenshots?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var data listScreenshotsResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/screenshots: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			screenshots = append(screenshots, Screenshot{
+				ID:        strconv.FormatInt(part.Data.ID, 10),
+				Name:      part.Data.Name,
+				TagsCount: part.Data.TagsCount,
+			})
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return screenshots, nil
+}
+
+func (client *Client) AddScreenshot(projectID string, localPath string, name string, autoTag bool) (string, error) {
+	// Add file to Crowdin cloud storage
+	storageID, err := client.uploadToCloudStorage(localPath, name)
+	if err != nil {
+		return "", err
+	}
+
+	// Create screenshot
+	addReq := addScreenshotReq{
+		StorageID: storageID,
+		Name:      name,
+		AutoTag:   autoTag,
+	}
+
+	resp, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/screenshots", 201, addReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data addScreenshotResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: POST /api/v2/projects/" + projectID + "/screenshots: failed decode JSON: " + err.Error())
+	}
+
+	return strconv.FormatInt(data.Data.ID, 10), nil
+}
+
+func (client *Client) UpdateScreenshot(projectID string, screenshotID string, localPath string, name string) error {
+	// Add file to Crowdin cloud storage
+	storageID, err := client.uploadToCloudStorage(localPath, name)
+	if err != nil {
+		return err
+	}
+
+	// Replace screenshot image
+	updateReq := updateScreenshotReq{
+		StorageID: storageID,
+		Name:      name,
+	}
+
+	resp, err := client.sendJSON("PUT", "/api/v2/projects/"+projectID+"/screenshots/"+screenshotID, 200, updateReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// SetScreenshotTags replaces screenshot tags with the strings.
+func (client *Client) SetScreenshotTags(projectID string, screenshotID string, stringIDs []string) error {
+	tagsReq := make([]addTagReq, 0, len(stringIDs))
+	for _, id := range stringIDs {
+		stringID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return errors.New("crowdin api: bad string ID: " + id)
+		}
+		tagsReq = append(tagsReq, addTagReq{StringID: stringID})
+	}
+
+	resp, err := client.sendJSON("PUT", "/api/v2/projects/"+projectID+"/screenshots/"+screenshotID+"/tags", 200, tagsReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// AutoTagScreenshot replaces screenshot tags with the strings recognized on the image by Crowdin.
+func (client *Client) AutoTagScreenshot(projectID string, screenshotID string) error {
+	resp, err := client.sendJSON("PUT", "/api/v2/projects/"+projectID+"/screenshots/"+screenshotID+"/tags", 200, autoTagReq{AutoTag: true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
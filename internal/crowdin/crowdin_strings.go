@@ -0,0 +1,321 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.strings.getMany
+type listStringsResp struct {
+	Data []struct {
+		Data struct {
+			ID         int64           `json:"id"`
+			FileID     int64           `json:"fileId"`
+			Identifier string          `json:"identifier"`
+			Text       json.RawMessage `json:"text"`
+			Context    string          `json:"context"`
+			MaxLength  int             `json:"maxLength"`
+			IsHidden   bool            `json:"isHidden"`
+			LabelIDs   []int64         `json:"labelIds"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.strings.post
+type addStringReq struct {
+	Identifier string `json:"identifier"`
+	Text       string `json:"text"`
+	Context    string `json:"context,omitempty"`
+	MaxLength  int    `json:"maxLength,omitempty"`
+}
+
+type addStringResp struct {
+	Data struct {
+		ID int64 `json:"id"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.languages.translations.getMany
+type listLanguageTranslationsResp struct {
+	Data []struct {
+		Data struct {
+			StringID int64  `json:"stringId"`
+			Text     string `json:"text"`
+			Plurals  []struct {
+				PluralForm string `json:"pluralForm"`
+				Text       string `json:"text"`
+			} `json:"plurals"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+type SourceString struct {
+	ID         string
+	FileID     string
+	Identifier string
+	Text       string // For plural strings it is the "other" form
+	IsPlural   bool
+	Context    string
+	MaxLength  int
+	IsHidden   bool
+	LabelIDs   []string
+}
+
+// stringText decodes string text, which is an object for plural strings.
+func stringText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+
+	var plural map[string]string
+	if json.Unmarshal(raw, &plural) == nil {
+		return plural["other"]
+	}
+
+	return ""
+}
+
+// ListStrings returns source strings of the file or of the whole project if the file ID is empty.
+func (client *Client) ListStrings(projectID string, fileID string) ([]SourceString, error) {
+	var strs []SourceString
+
+	query := ""
+	if fileID != "" {
+		query = "&fileId=" + fileID
+	}
+
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/projects/"+projectID+"/strings?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset)+query, 200)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var data listStringsResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/strings: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			var labelIDs []string
+			for _, id := range part.Data.LabelIDs {
+				labelIDs = append(labelIDs, strconv.FormatInt(id, 10))
+			}
+
+			strs = append(strs, SourceString{
+				ID:         strconv.FormatInt(part.Data.ID, 10),
+				FileID:     strconv.FormatInt(part.Data.FileID, 10),
+				Identifier: part.Data.Identifier,
+				Text:       stringText(part.Data.Text),
+				IsPlural:   len(part.Data.Text) != 0 && part.Data.Text[0] == '{',
+				Context:    part.Data.Context,
+				MaxLength:  part.Data.MaxLength,
+				IsHidden:   part.Data.IsHidden,
+				LabelIDs:   labelIDs,
+			})
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return strs, nil
+}
+
+// CountTranslations returns the number of translations of the strings to the language.
+func (client *Client) CountTranslations(projectID string, languageID string, stringIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+
+	// Strings are requested in small batches to keep URL short
+	const batchSize = 100
+	for start := 0; start < len(stringIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(stringIDs) {
+			end = len(stringIDs)
+		}
+		ids := strings.Join(stringIDs[start:end], ",")
+
+		for offset := 0; ; offset += paginationLimit {
+			resp, err := client.get("/api/v2/projects/"+projectID+"/languages/"+languageID+"/translations?stringIds="+ids+"&limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+
+			var data listLanguageTranslationsResp
+			err = json.NewDecoder(resp.Body).Decode(&data)
+			if err != nil {
+				return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/languages/" + languageID + "/translations: failed decode JSON: " + err.Error())
+			}
+
+			for _, part := range data.Data {
+				counts[strconv.FormatInt(part.Data.StringID, 10)]++
+			}
+
+			if len(data.Data) < paginationLimit {
+				break
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// EditString updates the string context and max length if they differ.
+// Empty context and zero max length are not applied.
+// It returns true if the string has been changed.
+func (client *Client) EditString(projectID string, str SourceString, context string, maxLength int) (bool, error) {
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.strings.patch
+	var editReq []patchReq
+
+	if context != "" && context != str.Context {
+		editReq = append(editReq, patchReq{Op: "replace", Path: "/context", Value: context})
+	}
+
+	if maxLength > 0 && maxLength != str.MaxLength {
+		editReq = append(editReq, patchReq{Op: "replace", Path: "/maxLength", Value: maxLength})
+	}
+
+	if len(editReq) == 0 {
+		return false, nil
+	}
+
+	resp, err := client.sendJSON("PATCH", "/api/v2/projects/"+projectID+"/strings/"+str.ID, 200, editReq)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	return true, nil
+}
+
+// AddString adds the source string to the string-based project and returns its ID.
+func (client *Client) AddString(projectID string, identifier string, text string, context string, maxLength int) (string, error) {
+	addReq := addStringReq{
+		Identifier: identifier,
+		Text:       text,
+		Context:    context,
+		MaxLength:  maxLength,
+	}
+
+	resp, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/strings", 201, addReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data addStringResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: POST /api/v2/projects/" + projectID + "/strings: failed decode JSON: " + err.Error())
+	}
+
+	return strconv.FormatInt(data.Data.ID, 10), nil
+}
+
+// EditStringText replaces the source string text and makes the string visible.
+func (client *Client) EditStringText(projectID string, stringID string, text string) error {
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.strings.patch
+	editReq := []patchReq{
+		{Op: "replace", Path: "/text", Value: text},
+		{Op: "replace", Path: "/isHidden", Value: false},
+	}
+
+	resp, err := client.sendJSON("PATCH", "/api/v2/projects/"+projectID+"/strings/"+stringID, 200, editReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+func (client *Client) SetStringHidden(projectID string, stringID string, hidden bool) error {
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.strings.patch
+	editReq := []patchReq{
+		{Op: "replace", Path: "/isHidden", Value: hidden},
+	}
+
+	resp, err := client.sendJSON("PATCH", "/api/v2/projects/"+projectID+"/strings/"+stringID, 200, editReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+func (client *Client) DeleteString(projectID string, stringID string) error {
+	resp, err := client.delete("/api/v2/projects/"+projectID+"/strings/"+stringID, 204)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// ListTranslations returns translations of all project strings to the language by string ID.
+// For plural strings the "other" form is returned.
+func (client *Client) ListTranslations(projectID string, languageID string, approvedOnly bool) (map[string]string, error) {
+	translations := make(map[string]string)
+
+	query := ""
+	if approvedOnly {
+		query = "&approvedOnly=1"
+	}
+
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/projects/"+projectID+"/languages/"+languageID+"/translations?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset)+query, 200)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var data listLanguageTranslationsResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/languages/" + languageID + "/translations: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			text := part.Data.Text
+			for _, plural := range part.Data.Plurals {
+				if plural.PluralForm == "other" {
+					text = plural.Text
+				}
+			}
+
+			translations[strconv.FormatInt(part.Data.StringID, 10)] = text
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return translations, nil
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+const (
+	TaskTypeTranslate = 0
+	TaskTypeProofread = 1
+)
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.tasks.post
+type addTaskReq struct {
+	Title       string            `json:"title"`
+	LanguageID  string            `json:"languageId"`
+	FileIDs     []int64           `json:"fileIds"`
+	Type        int               `json:"type"`
+	Description string            `json:"description,omitempty"`
+	Deadline    string            `json:"deadline,omitempty"`
+	Assignees   []taskAssigneeReq `json:"assignees,omitempty"`
+}
+
+type taskAssigneeReq struct {
+	ID int64 `json:"id"`
+}
+
+type addTaskResp struct {
+	Data struct {
+		ID int64 `json:"id"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.files.languages.progress.getMany
+type fileProgressResp struct {
+	Data []struct {
+		Data struct {
+			LanguageID string `json:"languageId"`
+			Phrases    struct {
+				Total      int `json:"total"`
+				Translated int `json:"translated"`
+				Approved   int `json:"approved"`
+			} `json:"phrases"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+type TaskOptions struct {
+	Title       string
+	Description string
+	LanguageID  string
+	FileIDs     []string
+	Type        int       // TaskTypeTranslate or TaskTypeProofread
+	Deadline    time.Time // Zero value means no deadline
+	Assignees   []string  // User IDs
+}
+
+// Progress is the number of strings by their state.
+type Progress struct {
+	Total      int
+	Translated int
+	Approved   int
+}
+
+// CreateTask creates the task and returns its ID.
+func (client *Client) CreateTask(projectID string, opts TaskOptions) (string, error) {
+	addReq := addTaskReq{
+		Title:       opts.Title,
+		LanguageID:  opts.LanguageID,
+		Type:        opts.Type,
+		Description: opts.Description,
+	}
+
+	for _, id := range opts.FileIDs {
+		fileID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return "", errors.New("crowdin api: bad file ID: " + id)
+		}
+		addReq.FileIDs = append(addReq.FileIDs, fileID)
+	}
+
+	for _, id := range opts.Assignees {
+		userID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return "", errors.New("crowdin api: bad user ID: " + id)
+		}
+		addReq.Assignees = append(addReq.Assignees, taskAssigneeReq{ID: userID})
+	}
+
+	if !opts.Deadline.IsZero() {
+		addReq.Deadline = opts.Deadline.UTC().Format(time.RFC3339)
+	}
+
+	resp, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/tasks", 201, addReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data addTaskResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: POST /api/v2/projects/" + projectID + "/tasks: failed decode JSON: " + err.Error())
+	}
+
+	return strconv.FormatInt(data.Data.ID, 10), nil
+}
+
+// FileProgress returns translation progress of the file by language ID.
+func (client *Client) FileProgress(projectID string, fileID string) (map[string]Progress, error) {
+	progress := make(map[string]Progress)
+
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/projects/"+projectID+"/files/"+fileID+"/languages/progress?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		var data fileProgressResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return nil, errors.New("crowdin api: GET /api/v2/projects/" + projectID + "/files/" + fileID + "/languages/progress: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			progress[part.Data.LanguageID] = Progress{
+				Total:      part.Data.Phrases.Total,
+				Translated: part.Data.Phrases.Translated,
+				Approved:   part.Data.Phrases.Approved,
+			}
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return progress, nil
+}
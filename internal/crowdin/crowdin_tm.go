@@ -0,0 +1,185 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+)
+
+var tmFormats = []string{"tmx", "csv", "xlsx"}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.tms.getMany
+type listTmsResp struct {
+	Data []struct {
+		Data struct {
+			ID            int64  `json:"id"`
+			Name          string `json:"name"`
+			LanguageID    string `json:"languageId"`
+			SegmentsCount int    `json:"segmentsCount"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.tms.post
+type addTmReq struct {
+	Name       string `json:"name"`
+	LanguageID string `json:"languageId"`
+}
+
+type addTmResp struct {
+	Data struct {
+		ID int64 `json:"id"`
+	} `json:"data"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.tms.imports.post
+type importTmReq struct {
+	StorageID               int64          `json:"storageId"`
+	Scheme                  map[string]int `json:"scheme,omitempty"`
+	FirstLineContainsHeader bool           `json:"firstLineContainsHeader,omitempty"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.tms.exports.post
+type exportTmReq struct {
+	SourceLanguageID string `json:"sourceLanguageId,omitempty"`
+	TargetLanguageID string `json:"targetLanguageId,omitempty"`
+	Format           string `json:"format"`
+}
+
+// FindTmId finds the translation memory assigned to the project by its name.
+// TMs of other projects are ignored. It returns an empty string if the translation memory is not found.
+func (client *Client) FindTmId(project *Project, name string) (string, error) {
+	for offset := 0; ; offset += paginationLimit {
+		resp, err := client.get("/api/v2/tms?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset), 200)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		var data listTmsResp
+		err = json.NewDecoder(resp.Body).Decode(&data)
+		if err != nil {
+			return "", errors.New("crowdin api: GET /api/v2/tms: failed decode JSON: " + err.Error())
+		}
+
+		for _, part := range data.Data {
+			tmID := strconv.FormatInt(part.Data.ID, 10)
+			if part.Data.Name == name && containsString(project.AssignedTmIDs, tmID) {
+				return tmID, nil
+			}
+		}
+
+		if len(data.Data) < paginationLimit {
+			break
+		}
+	}
+
+	return "", nil
+}
+
+// CreateTm creates the translation memory and assigns it to the project.
+func (client *Client) CreateTm(projectID string, name string, languageID string) (string, error) {
+	resp, err := client.sendJSON("POST", "/api/v2/tms", 201, addTmReq{Name: name, LanguageID: languageID})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var data addTmResp
+	err = json.NewDecoder(resp.Body).Decode(&data)
+	if err != nil {
+		return "", errors.New("crowdin api: POST /api/v2/tms: failed decode JSON: " + err.Error())
+	}
+
+	tmID := strconv.FormatInt(data.Data.ID, 10)
+
+	// Assign to the project
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.patch
+	editReq := []patchReq{
+		{Op: "add", Path: "/assignedTms/" + tmID, Value: map[string]int{"priority": 1}},
+	}
+
+	respEdit, err := client.sendJSON("PATCH", "/api/v2/projects/"+projectID, 200, editReq)
+	if err != nil {
+		return "", err
+	}
+	defer respEdit.Body.Close()
+
+	return tmID, nil
+}
+
+func (client *Client) ImportTm(tmID string, localPath string, scheme map[string]int, firstLineContainsHeader bool) error {
+	_, err := fileFormat(localPath, tmFormats...)
+	if err != nil {
+		return err
+	}
+
+	// Add file to Crowdin cloud storage
+	storageID, err := client.uploadToCloudStorage(localPath, filepath.Base(localPath))
+	if err != nil {
+		return err
+	}
+
+	// Start import
+	importReq := importTmReq{
+		StorageID:               storageID,
+		Scheme:                  scheme,
+		FirstLineContainsHeader: firstLineContainsHeader,
+	}
+
+	importID, err := client.startOperation("/api/v2/tms/"+tmID+"/imports", 202, importReq)
+	if err != nil {
+		return err
+	}
+
+	// Wait until import is finished
+	return client.waitOperation("/api/v2/tms/" + tmID + "/imports/" + importID)
+}
+
+// ExportTm exports the translation memory. Empty language IDs export all languages.
+func (client *Client) ExportTm(tmID string, destPath string, sourceLanguageID string, targetLanguageID string) error {
+	format, err := fileFormat(destPath, tmFormats...)
+	if err != nil {
+		return err
+	}
+
+	// Start export
+	exportReq := exportTmReq{
+		SourceLanguageID: sourceLanguageID,
+		TargetLanguageID: targetLanguageID,
+		Format:           format,
+	}
+
+	exportID, err := client.startOperation("/api/v2/tms/"+tmID+"/exports", 202, exportReq)
+	if err != nil {
+		return err
+	}
+
+	// Wait until export is finished
+	err = client.waitOperation("/api/v2/tms/" + tmID + "/exports/" + exportID)
+	if err != nil {
+		return err
+	}
+
+	// Download
+	return client.dlToFile("/api/v2/tms/"+tmID+"/exports/"+exportID+"/download", destPath)
+}
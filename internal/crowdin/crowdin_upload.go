@@ -34,17 +34,31 @@ type addStorageResp struct {
 
 // Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.files.post
 type addFileReq struct {
-	StorageID   int64  `json:"storageId"`
-	Name        string `json:"name"`
-	BranchID    int64  `json:"branchId,omitempty"`
-	DirectoryID int64  `json:"directoryId,omitempty"`
-	Title       string `json:"title,omitempty"`
-	Type        string `json:"type,omitempty"`
+	StorageID               int64           `json:"storageId"`
+	Name                    string          `json:"name"`
+	BranchID                int64           `json:"branchId,omitempty"`
+	DirectoryID             int64           `json:"directoryId,omitempty"`
+	Title                   string          `json:"title,omitempty"`
+	Type                    string          `json:"type,omitempty"`
+	ImportOptions           json.RawMessage `json:"importOptions,omitempty"`
+	ExportOptions           json.RawMessage `json:"exportOptions,omitempty"`
+	ExcludedTargetLanguages []string        `json:"excludedTargetLanguages,omitempty"`
 }
 
 // Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.files.put
 type updateFileReq struct {
-	StorageID int64 `json:"storageId"`
+	StorageID     int64           `json:"storageId"`
+	ImportOptions json.RawMessage `json:"importOptions,omitempty"`
+	ExportOptions json.RawMessage `json:"exportOptions,omitempty"`
+}
+
+// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.files.post
+type FileOptions struct {
+	Type                    string          `json:"type,omitempty"`
+	Title                   string          `json:"title,omitempty"`
+	ImportOptions           json.RawMessage `json:"importOptions,omitempty"`
+	ExportOptions           json.RawMessage `json:"exportOptions,omitempty"`
+	ExcludedTargetLanguages []string        `json:"excludedTargetLanguages,omitempty"`
 }
 
 func (client *Client) uploadToCloudStorage(localPath string, cloudFileName string) (int64, error) {
@@ -69,7 +83,7 @@ func (client *Client) uploadToCloudStorage(localPath string, cloudFileName strin
 	return data.Data.ID, nil
 }
 
-func (client *Client) AddFile(projectID string, localPath string, cloudFileName string) error {
+func (client *Client) AddFile(projectID string, localPath string, cloudFileName string, opts FileOptions) error {
 	// Add file to Crowdin cloud storage
 	cloudFileID, err := client.uploadToCloudStorage(localPath, cloudFileName)
 	if err != nil {
@@ -78,8 +92,13 @@ func (client *Client) AddFile(projectID string, localPath string, cloudFileName
 
 	// Move file from Crowdin cloud storage to Crowdin project
 	addReq := addFileReq{
-		StorageID: cloudFileID,
-		Name:      cloudFileName,
+		StorageID:               cloudFileID,
+		Name:                    cloudFileName,
+		Title:                   opts.Title,
+		Type:                    opts.Type,
+		ImportOptions:           opts.ImportOptions,
+		ExportOptions:           opts.ExportOptions,
+		ExcludedTargetLanguages: opts.ExcludedTargetLanguages,
 	}
 
 	resp, err := client.sendJSON("POST", "/api/v2/projects/"+projectID+"/files", 201, addReq)
@@ -88,10 +107,12 @@ func (client *Client) AddFile(projectID string, localPath string, cloudFileName
 	}
 	defer resp.Body.Close()
 
+	client.cache.invalidateFiles(projectID)
+
 	return nil
 }
 
-func (client *Client) UpdateFile(projectID string, localPath string, cloudFileName string, fileID string) error {
+func (client *Client) UpdateFile(projectID string, localPath string, cloudFileName string, fileID string, opts FileOptions) error {
 	// Add file to Crowdin cloud storage
 	cloudFileID, err := client.uploadToCloudStorage(localPath, cloudFileName)
 	if err != nil {
@@ -100,7 +121,9 @@ func (client *Client) UpdateFile(projectID string, localPath string, cloudFileNa
 
 	// Move file from Crowdin cloud storage to Crowdin project
 	updateReq := updateFileReq{
-		StorageID: cloudFileID,
+		StorageID:     cloudFileID,
+		ImportOptions: opts.ImportOptions,
+		ExportOptions: opts.ExportOptions,
 	}
 
 	resp, err := client.sendJSON("PUT", "/api/v2/projects/"+projectID+"/files/"+fileID, 200, updateReq)
@@ -111,3 +134,61 @@ func (client *Client) UpdateFile(projectID string, localPath string, cloudFileNa
 
 	return nil
 }
+
+// EditFile updates the file title and excluded target languages if they
+// differ from the options. It returns true if the file has been changed.
+func (client *Client) EditFile(projectID string, file File, opts FileOptions) (bool, error) {
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.files.patch
+	var editReq []patchReq
+
+	if opts.Title != "" && opts.Title != file.Title {
+		editReq = append(editReq, patchReq{Op: "replace", Path: "/title", Value: opts.Title})
+	}
+
+	if opts.ExcludedTargetLanguages != nil && !equalStringSets(opts.ExcludedTargetLanguages, file.ExcludedTargetLanguages) {
+		editReq = append(editReq, patchReq{Op: "replace", Path: "/excludedTargetLanguages", Value: opts.ExcludedTargetLanguages})
+	}
+
+	if len(editReq) == 0 {
+		return false, nil
+	}
+
+	resp, err := client.sendJSON("PATCH", "/api/v2/projects/"+projectID+"/files/"+file.ID, 200, editReq)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	client.cache.invalidateFiles(projectID)
+
+	return true, nil
+}
+
+func (client *Client) RenameFile(projectID string, fileID string, newCloudFileName string) error {
+	// Read more: https://developer.crowdin.com/api/v2/#operation/api.projects.files.patch
+	editReq := []patchReq{
+		{Op: "replace", Path: "/name", Value: newCloudFileName},
+	}
+
+	resp, err := client.sendJSON("PATCH", "/api/v2/projects/"+projectID+"/files/"+fileID, 200, editReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	client.cache.invalidateFiles(projectID)
+
+	return nil
+}
+
+func (client *Client) DeleteFile(projectID string, fileID string) error {
+	resp, err := client.delete("/api/v2/projects/"+projectID+"/files/"+fileID, 204)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	client.cache.invalidateFiles(projectID)
+
+	return nil
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+)
+
+// LanguageMapping overrides language placeholders values, by Crowdin language ID.
+// Read more: https://support.crowdin.com/configuration-file/#languages-mapping
+type LanguageMapping map[string]map[string]string
+
+var codePlaceholders = []string{
+	"language_id",
+	"two_letters_code",
+	"three_letters_code",
+	"locale",
+	"locale_with_underscore",
+	"android_code",
+	"osx_code",
+	"osx_locale",
+}
+
+// UnmarshalJSON also accepts a single code instead of the placeholders map,
+// in this case the code is used for all language code placeholders.
+func (mapping *LanguageMapping) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	*mapping = make(LanguageMapping)
+	for langID, rawVal := range raw {
+		var code string
+		if json.Unmarshal(rawVal, &code) == nil {
+			(*mapping)[langID] = make(map[string]string)
+			for _, name := range codePlaceholders {
+				(*mapping)[langID][name] = code
+			}
+			continue
+		}
+
+		var values map[string]string
+		err = json.Unmarshal(rawVal, &values)
+		if err != nil {
+			return errors.New("language " + langID + ": " + err.Error())
+		}
+
+		(*mapping)[langID] = make(map[string]string)
+		for name, val := range values {
+			// Crowdin uses "name" for the %language% placeholder
+			if name == "name" {
+				name = "language"
+			}
+
+			if _, ok := (Language{}).placeholders(nil)[name]; !ok {
+				return errors.New("language " + langID + ": unknown placeholder: " + name)
+			}
+
+			(*mapping)[langID][name] = val
+		}
+	}
+
+	return nil
+}
+
+// merge returns a copy of the mapping, overridden by other mapping.
+func (mapping LanguageMapping) merge(other LanguageMapping) LanguageMapping {
+	result := make(LanguageMapping)
+	for _, m := range []LanguageMapping{mapping, other} {
+		for langID, values := range m {
+			if result[langID] == nil {
+				result[langID] = make(map[string]string)
+			}
+
+			for name, val := range values {
+				result[langID][name] = val
+			}
+		}
+	}
+
+	return result
+}
+
+// Read more: https://support.crowdin.com/configuration-file/#placeholders
+func (lang Language) placeholders(mapping LanguageMapping) map[string]string {
+	values := map[string]string{
+		"language":               lang.Name,
+		"language_id":            lang.ID,
+		"two_letters_code":       lang.TwoLettersCode,
+		"three_letters_code":     lang.ThreeLettersCode,
+		"locale":                 lang.Locale,
+		"locale_with_underscore": strings.Replace(lang.Locale, "-", "_", -1),
+		"android_code":           lang.AndroidCode,
+		"osx_code":               lang.OsxCode,
+		"osx_locale":             lang.OsxLocale,
+	}
+
+	for name, val := range mapping[lang.ID] {
+		if _, ok := values[name]; ok {
+			values[name] = val
+		}
+	}
+
+	return values
+}
+
+func expandPathTemplate(template string, values map[string]string, originalPath string) string {
+	fileName := path.Base(originalPath)
+	fileExt := strings.TrimPrefix(path.Ext(fileName), ".")
+
+	originalDir := path.Dir(originalPath)
+	if originalDir == "." {
+		originalDir = ""
+	}
+
+	oldnew := []string{
+		"%original_file_name%", fileName,
+		"%file_name%", strings.TrimSuffix(fileName, path.Ext(fileName)),
+		"%file_extension%", fileExt,
+		"%original_path%", originalDir,
+	}
+	for name, val := range values {
+		oldnew = append(oldnew, "%"+name+"%", val)
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(template)
+}
+
+// detectLanguage finds the language by the first directory of the archive entry.
+// It returns the language, the placeholder whose value matches the directory name
+// and the entry path inside the language directory.
+func detectLanguage(entry string, languages []Language, mapping LanguageMapping) (Language, string, string, bool) {
+	parts := strings.SplitN(entry, "/", 2)
+	if len(parts) != 2 {
+		return Language{}, "", "", false
+	}
+
+	for _, lang := range languages {
+		if parts[0] == lang.ID {
+			return lang, "language_id", parts[1], true
+		}
+	}
+
+	for _, m := range []LanguageMapping{nil, mapping} {
+		for _, lang := range languages {
+			values := lang.placeholders(m)
+			for _, name := range codePlaceholders {
+				if values[name] != "" && parts[0] == values[name] {
+					return lang, name, parts[1], true
+				}
+			}
+		}
+	}
+
+	return Language{}, "", "", false
+}
+
+// safeRelPath cleans the relative path and makes sure it does not leave the destination directory.
+func safeRelPath(p string) (string, error) {
+	cleaned := path.Clean(strings.Replace(p, "\\", "/", -1))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") || path.IsAbs(cleaned) {
+		return "", errors.New("unsafe file path: " + p)
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,165 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+package crowdin
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestExpandPathTemplate(t *testing.T) {
+	values := map[string]string{
+		"language":         "Russian",
+		"two_letters_code": "ru",
+		"locale":           "ru-RU",
+	}
+
+	tests := []struct {
+		template     string
+		originalPath string
+		want         string
+	}{
+		{"locale/%two_letters_code%.ini", "en.ini", "locale/ru.ini"},
+		{"%original_path%/%locale%/%original_file_name%", "web/data/app.json", "web/data/ru-RU/app.json"},
+		{"%locale%/%file_name%.%file_extension%", "messages.properties", "ru-RU/messages.properties"},
+		{"%original_path%%file_name%_%two_letters_code%.po", "app.po", "app_ru.po"},
+		{"%language%/%file_name%", "docs/README.md", "Russian/README"},
+		{"%unknown%/%locale%.json", "en.json", "%unknown%/ru-RU.json"},
+	}
+
+	for _, test := range tests {
+		got := expandPathTemplate(test.template, values, test.originalPath)
+		if got != test.want {
+			t.Errorf("expandPathTemplate(%q, %q) = %q, want %q", test.template, test.originalPath, got, test.want)
+		}
+	}
+}
+
+func TestSafeRelPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		want  string
+		valid bool
+	}{
+		{"ru/app.json", "ru/app.json", true},
+		{"./ru//app.json", "ru/app.json", true},
+		{"ru/../de/app.json", "de/app.json", true},
+		{"ru\\app.json", "ru/app.json", true},
+		{"", "", false},
+		{".", "", false},
+		{"..", "", false},
+		{"../app.json", "", false},
+		{"ru/../../app.json", "", false},
+		{"..\\app.json", "", false},
+		{"/etc/passwd", "", false},
+	}
+
+	for _, test := range tests {
+		got, err := safeRelPath(test.path)
+		if (err == nil) != test.valid {
+			t.Errorf("safeRelPath(%q): error %v, want valid %v", test.path, err, test.valid)
+			continue
+		}
+
+		if got != test.want {
+			t.Errorf("safeRelPath(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestLanguageMapping(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		want  LanguageMapping
+		valid bool
+	}{
+		{
+			name:  "single code",
+			data:  `{"pt-BR": "pt"}`,
+			want:  LanguageMapping{"pt-BR": {"language_id": "pt", "two_letters_code": "pt", "three_letters_code": "pt", "locale": "pt", "locale_with_underscore": "pt", "android_code": "pt", "osx_code": "pt", "osx_locale": "pt"}},
+			valid: true,
+		},
+		{
+			name:  "placeholders",
+			data:  `{"zh-CN": {"locale": "zh-Hans", "name": "Chinese"}}`,
+			want:  LanguageMapping{"zh-CN": {"locale": "zh-Hans", "language": "Chinese"}},
+			valid: true,
+		},
+		{
+			name:  "unknown placeholder",
+			data:  `{"zh-CN": {"region": "CN"}}`,
+			valid: false,
+		},
+		{
+			name:  "bad value",
+			data:  `{"zh-CN": 1}`,
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		var got LanguageMapping
+		err := json.Unmarshal([]byte(test.data), &got)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: error %v, want valid %v", test.name, err, test.valid)
+			continue
+		}
+
+		if test.valid && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	languages := []Language{
+		{ID: "ru", Name: "Russian", TwoLettersCode: "ru", ThreeLettersCode: "rus", Locale: "ru-RU", AndroidCode: "ru-rRU"},
+		{ID: "pt-BR", Name: "Portuguese, Brazilian", TwoLettersCode: "pt", ThreeLettersCode: "por", Locale: "pt-BR", AndroidCode: "pt-rBR"},
+	}
+	mapping := LanguageMapping{"pt-BR": {"locale": "br"}}
+
+	tests := []struct {
+		entry       string
+		langID      string
+		placeholder string
+		rest        string
+	}{
+		{"ru/app.json", "ru", "language_id", "app.json"},
+		{"pt-BR/web/app.json", "pt-BR", "language_id", "web/app.json"},
+		{"rus/app.json", "ru", "three_letters_code", "app.json"},
+		{"pt-rBR/strings.xml", "pt-BR", "android_code", "strings.xml"},
+		{"br/app.json", "pt-BR", "locale", "app.json"},
+		{"de/app.json", "", "", ""},
+		{"app.json", "", "", ""},
+	}
+
+	for _, test := range tests {
+		lang, placeholder, rest, ok := detectLanguage(test.entry, languages, mapping)
+		if ok != (test.langID != "") {
+			t.Errorf("detectLanguage(%q): found %v, want %v", test.entry, ok, test.langID != "")
+			continue
+		}
+
+		if lang.ID != test.langID || placeholder != test.placeholder || rest != test.rest {
+			t.Errorf("detectLanguage(%q) = %q, %q, %q, want %q, %q, %q",
+				test.entry, lang.ID, placeholder, rest, test.langID, test.placeholder, test.rest)
+		}
+	}
+}
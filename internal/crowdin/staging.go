@@ -0,0 +1,207 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package crowdin
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// StagingDirPrefix is the name prefix of the hidden staging directory inside the destination directory.
+const StagingDirPrefix = "." + tmpFilePattern + "staging-"
+
+// staging is a temporary directory inside the destination directory.
+// Files are extracted into it first and then moved into place, so the
+// destination directory is either fully updated or untouched.
+type staging struct {
+	dir     string
+	destDir string
+	moved   []stagedFile
+}
+
+type stagedFile struct {
+	dest   string
+	backup string // Empty if the destination file did not exist
+}
+
+func newStaging(destDir string) (*staging, error) {
+	// Staging directory must be on the same file system as the destination,
+	// otherwise files cannot be renamed.
+	err := os.MkdirAll(destDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp(destDir, StagingDirPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &staging{
+		dir:     dir,
+		destDir: destDir,
+	}, nil
+}
+
+func (st *staging) filesDir() string {
+	return filepath.Join(st.dir, "files")
+}
+
+func (st *staging) backupDir() string {
+	return filepath.Join(st.dir, "backup")
+}
+
+// commit moves the extracted files into the destination directory and sets their status.
+// Files with the same content are left untouched.
+// If a file cannot be moved, all already moved files are rolled back.
+func (st *staging) commit(files []ExtractedFile) error {
+	for i := range files {
+		status, err := st.move(files[i])
+		if err != nil {
+			rollbackErr := st.rollback()
+			if rollbackErr != nil {
+				return errors.New(err.Error() + " (rollback failed: " + rollbackErr.Error() + ")")
+			}
+			return err
+		}
+
+		files[i].Status = status
+	}
+
+	return nil
+}
+
+func (st *staging) move(file ExtractedFile) (string, error) {
+	rel := filepath.FromSlash(file.Path)
+	src := filepath.Join(st.filesDir(), rel)
+	dest := filepath.Join(st.destDir, rel)
+
+	moved := stagedFile{dest: dest}
+	status := StatusNew
+
+	// Backup the existing file
+	destInfo, err := os.Lstat(dest)
+	if err == nil {
+		status = StatusChanged
+
+		same, err := sameContent(src, dest)
+		if err != nil {
+			return "", err
+		}
+
+		if same {
+			return StatusUnchanged, nil
+		}
+
+		// Keep permissions of the existing file
+		if destInfo.Mode().IsRegular() {
+			err = os.Chmod(src, destInfo.Mode().Perm())
+			if err != nil {
+				return "", err
+			}
+		}
+
+		moved.backup = filepath.Join(st.backupDir(), rel)
+
+		err = os.MkdirAll(filepath.Dir(moved.backup), 0755)
+		if err != nil {
+			return "", err
+		}
+
+		err = os.Rename(dest, moved.backup)
+		if err != nil {
+			return "", err
+		}
+
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	// Move the new file into place
+	err = os.MkdirAll(filepath.Dir(dest), 0755)
+	if err == nil {
+		err = os.Rename(src, dest)
+	}
+	if err != nil {
+		// Restore the backup right away
+		if moved.backup != "" {
+			os.Rename(moved.backup, dest)
+		}
+		return "", err
+	}
+
+	st.moved = append(st.moved, moved)
+	return status, nil
+}
+
+func sameContent(a string, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+
+	if !infoB.Mode().IsRegular() || infoA.Size() != infoB.Size() {
+		return false, nil
+	}
+
+	dataA, err := os.ReadFile(a)
+	if err != nil {
+		return false, err
+	}
+
+	dataB, err := os.ReadFile(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(dataA, dataB), nil
+}
+
+func (st *staging) rollback() error {
+	var lastErr error
+
+	for i := len(st.moved) - 1; i >= 0; i-- {
+		moved := st.moved[i]
+
+		var err error
+		if moved.backup != "" {
+			err = os.Rename(moved.backup, moved.dest)
+		} else {
+			err = os.Remove(moved.dest)
+		}
+
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	st.moved = nil
+	return lastErr
+}
+
+func (st *staging) remove() {
+	os.RemoveAll(st.dir)
+}
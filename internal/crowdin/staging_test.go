@@ -0,0 +1,147 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+package crowdin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(filePath), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(filePath, []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func readTestFiles(t *testing.T, dir string) map[string]string {
+	files := make(map[string]string)
+
+	err := filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return files
+}
+
+func TestStagingCommit(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing map[string]string
+		staged   map[string]string
+		files    []string
+		want     map[string]string
+		statuses []string
+		fail     bool
+	}{
+		{
+			name:     "new, changed and unchanged files",
+			existing: map[string]string{"ru/app.ini": "old", "de/app.ini": "same", "readme.txt": "keep"},
+			staged:   map[string]string{"ru/app.ini": "new", "de/app.ini": "same", "fr/app.ini": "fr"},
+			files:    []string{"ru/app.ini", "de/app.ini", "fr/app.ini"},
+			want:     map[string]string{"ru/app.ini": "new", "de/app.ini": "same", "fr/app.ini": "fr", "readme.txt": "keep"},
+			statuses: []string{StatusChanged, StatusUnchanged, StatusNew},
+		},
+		{
+			name:     "empty destination",
+			staged:   map[string]string{"ru/app.ini": "ru"},
+			files:    []string{"ru/app.ini"},
+			want:     map[string]string{"ru/app.ini": "ru"},
+			statuses: []string{StatusNew},
+		},
+		{
+			name:     "rollback on failure",
+			existing: map[string]string{"ru/app.ini": "old", "de/app.ini": "same"},
+			staged:   map[string]string{"ru/app.ini": "new", "de/app.ini": "same", "fr/app.ini": "fr"},
+			files:    []string{"ru/app.ini", "de/app.ini", "fr/app.ini", "missing.ini"},
+			want:     map[string]string{"ru/app.ini": "old", "de/app.ini": "same"},
+			fail:     true,
+		},
+	}
+
+	for _, test := range tests {
+		destDir := filepath.Join(t.TempDir(), "locale")
+		writeTestFiles(t, destDir, test.existing)
+
+		st, err := newStaging(destDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeTestFiles(t, st.filesDir(), test.staged)
+
+		var files []ExtractedFile
+		for _, name := range test.files {
+			files = append(files, ExtractedFile{Path: name})
+		}
+
+		err = st.commit(files)
+		st.remove()
+
+		if (err != nil) != test.fail {
+			t.Errorf("%s: commit() error %v, want failure %v", test.name, err, test.fail)
+			continue
+		}
+
+		got := readTestFiles(t, destDir)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: destination files %v, want %v", test.name, got, test.want)
+		}
+
+		if !test.fail {
+			for i, file := range files {
+				if file.Status != test.statuses[i] {
+					t.Errorf("%s: %s status %q, want %q", test.name, file.Path, file.Status, test.statuses[i])
+				}
+			}
+		}
+
+		_, err = os.Stat(st.dir)
+		if !os.IsNotExist(err) {
+			t.Errorf("%s: staging directory was not removed", test.name)
+		}
+	}
+}
@@ -20,14 +20,15 @@ package crowdin
 
 import (
 	"archive/zip"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 )
 
 // From StackOverflow: https://stackoverflow.com/a/24792688/18180735
-func unzip(src string, dest string) ([]string, error) {
-	var extracted []string
+func unzip(src string, dest string, resolve func(name string) (ExtractedFile, error)) ([]ExtractedFile, error) {
+	var extracted []ExtractedFile
 
 	r, err := zip.OpenReader(src)
 	if err != nil {
@@ -35,43 +36,71 @@ func unzip(src string, dest string) ([]string, error) {
 	}
 	defer r.Close()
 
+	// Resolve destination paths and detect conflicts before writing anything
+	entries := make(map[*zip.File]ExtractedFile)
+	byPath := make(map[string]string)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		file, err := resolve(f.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, ok := byPath[file.Path]; ok {
+			return nil, errors.New("archive entries '" + other + "' and '" + f.Name + "' are extracted to the same path: " + file.Path)
+		}
+		byPath[file.Path] = f.Name
+
+		entries[f] = file
+	}
+
 	os.MkdirAll(dest, 0755)
 
 	// Closure to address file descriptors issue with all the deferred .Close() methods
-	extractAndWriteFile := func(f *zip.File) error {
+	extractAndWriteFile := func(f *zip.File, file ExtractedFile) error {
 		rc, err := f.Open()
 		if err != nil {
 			return err
 		}
 		defer rc.Close()
 
-		path := filepath.Join(dest, f.Name)
+		path := filepath.Join(dest, filepath.FromSlash(file.Path))
 
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(path, f.Mode())
-		} else {
-			extracted = append(extracted, f.Name)
-
-			os.MkdirAll(filepath.Dir(path), f.Mode())
-			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-			if err != nil {
-				return err
-			}
-			defer f.Close()
-
-			_, err = io.Copy(f, rc)
-			if err != nil {
-				return err
-			}
+		os.MkdirAll(filepath.Dir(path), 0755)
+		mode := f.Mode().Perm()
+		if mode == 0 {
+			mode = 0644
 		}
+
+		out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+
+		_, err = io.Copy(out, rc)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	}
 
 	for _, f := range r.File {
-		err := extractAndWriteFile(f)
+		file, ok := entries[f]
+		if !ok {
+			continue
+		}
+
+		err := extractAndWriteFile(f, file)
 		if err != nil {
 			return nil, err
 		}
+
+		extracted = append(extracted, file)
 	}
 
 	return extracted, nil
@@ -0,0 +1,97 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"strings"
+)
+
+// unquote removes matching quotes around the value.
+func unquote(val string, line int) (string, error) {
+	if len(val) == 0 {
+		return val, nil
+	}
+
+	quote := val[0]
+	if quote != '"' && quote != '\'' {
+		return val, nil
+	}
+
+	if len(val) < 2 || val[len(val)-1] != quote {
+		return "", newSyntaxError(line, "unbalanced quotes: "+val)
+	}
+
+	return val[1 : len(val)-1], nil
+}
+
+func parseINI(data []byte) ([]Entry, error) {
+	var entries []Entry
+	var comment []string
+	section := ""
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			comment = nil
+
+		case line[0] == ';' || line[0] == '#':
+			comment = append(comment, strings.TrimSpace(line[1:]))
+
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return nil, newSyntaxError(lineNum, "unclosed section header: "+line)
+			}
+
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == "" {
+				return nil, newSyntaxError(lineNum, "empty section name")
+			}
+			comment = nil
+
+		default:
+			sep := strings.IndexByte(line, '=')
+			if sep == -1 {
+				return nil, newSyntaxError(lineNum, "expected 'key = value': "+line)
+			}
+
+			key := strings.TrimSpace(line[:sep])
+			if key == "" {
+				return nil, newSyntaxError(lineNum, "empty key")
+			}
+
+			val, err := unquote(strings.TrimSpace(line[sep+1:]), lineNum)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, Entry{
+				Key:     joinKey(section, key),
+				Value:   val,
+				Comment: strings.Join(comment, "\n"),
+				Line:    lineNum,
+			})
+			comment = nil
+		}
+	}
+
+	return entries, nil
+}
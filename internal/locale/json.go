@@ -0,0 +1,122 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+func parseJSON(data []byte) ([]Entry, error) {
+	var entries []Entry
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	syntaxError := func(err error) error {
+		var jsonErr *json.SyntaxError
+		if errors.As(err, &jsonErr) {
+			return newSyntaxError(lineOf(data, jsonErr.Offset-1), jsonErr.Error())
+		}
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return newSyntaxError(lineAt(data, int64(len(data))), "unexpected end of JSON input")
+		}
+
+		return newSyntaxError(lineAt(data, dec.InputOffset()), err.Error())
+	}
+
+	var walk func(prefix string, line int) error
+	walk = func(prefix string, line int) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return syntaxError(err)
+		}
+
+		switch val := tok.(type) {
+		case json.Delim:
+			switch val {
+			case '{':
+				for dec.More() {
+					keyLine := lineAt(data, dec.InputOffset())
+
+					keyTok, err := dec.Token()
+					if err != nil {
+						return syntaxError(err)
+					}
+
+					key, ok := keyTok.(string)
+					if !ok {
+						return newSyntaxError(keyLine, "object key must be a string")
+					}
+
+					err = walk(joinKey(prefix, key), keyLine)
+					if err != nil {
+						return err
+					}
+				}
+
+			case '[':
+				for i := 0; dec.More(); i++ {
+					err = walk(joinKey(prefix, strconv.Itoa(i)), lineAt(data, dec.InputOffset()))
+					if err != nil {
+						return err
+					}
+				}
+			}
+
+			// Closing delimiter
+			_, err = dec.Token()
+			if err != nil {
+				return syntaxError(err)
+			}
+
+		case string:
+			entries = append(entries, Entry{Key: prefix, Value: val, Line: line})
+
+		case nil:
+			entries = append(entries, Entry{Key: prefix, Line: line})
+
+		default:
+			entries = append(entries, Entry{Key: prefix, Value: fmt.Sprint(val), Line: line})
+		}
+
+		return nil
+	}
+
+	err := walk("", 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only one JSON value is allowed
+	_, err = dec.Token()
+	if err != io.EOF {
+		if err == nil {
+			return nil, newSyntaxError(lineAt(data, dec.InputOffset()), "unexpected data after top-level value")
+		}
+		return nil, syntaxError(err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,143 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatJSON       = "json"
+	FormatYAML       = "yaml"
+	FormatINI        = "ini"
+	FormatProperties = "properties"
+	FormatPO         = "po"
+	FormatAndroid    = "android"
+	FormatStrings    = "strings"
+	FormatXLIFF      = "xliff"
+)
+
+type Entry struct {
+	Key     string
+	Value   string
+	Source  string // Source text, only for bilingual formats (gettext PO, XLIFF)
+	Comment string
+	Line    int
+}
+
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return "line " + strconv.Itoa(e.Line) + ": " + e.Msg
+}
+
+func newSyntaxError(line int, msg string) error {
+	return &SyntaxError{Line: line, Msg: msg}
+}
+
+// DetectFormat returns the file format by its name or an empty string if the format is unknown.
+func DetectFormat(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".yml", ".yaml":
+		return FormatYAML
+	case ".ini", ".locale":
+		return FormatINI
+	case ".properties":
+		return FormatProperties
+	case ".po", ".pot":
+		return FormatPO
+	case ".xml":
+		return FormatAndroid
+	case ".strings":
+		return FormatStrings
+	case ".xliff", ".xlf":
+		return FormatXLIFF
+	}
+
+	return ""
+}
+
+// Parse parses the file content.
+// Entries are returned in the file order, duplicate keys are preserved.
+func Parse(format string, data []byte) ([]Entry, error) {
+	// Skip UTF-8 BOM
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+
+	switch format {
+	case FormatJSON:
+		return parseJSON(data)
+	case FormatYAML:
+		return parseYAML(data)
+	case FormatINI:
+		return parseINI(data)
+	case FormatProperties:
+		return parseProperties(data)
+	case FormatPO:
+		return parsePO(data)
+	case FormatAndroid:
+		return parseAndroid(data)
+	case FormatStrings:
+		return parseStrings(data)
+	case FormatXLIFF:
+		return parseXLIFF(data)
+	}
+
+	return nil, errors.New("unknown file format: " + format)
+}
+
+// lineAt returns the line number of the first meaningful character at or after the offset.
+func lineAt(data []byte, offset int64) int {
+	pos := int(offset)
+	if pos > len(data) {
+		pos = len(data)
+	}
+
+	for pos < len(data) && strings.IndexByte(" \t\r\n,:", data[pos]) != -1 {
+		pos++
+	}
+
+	return lineOf(data, int64(pos))
+}
+
+// lineOf returns the line number of the character at the offset.
+func lineOf(data []byte, offset int64) int {
+	pos := int(offset)
+	if pos > len(data) {
+		pos = len(data)
+	}
+
+	return bytes.Count(data[:pos], []byte("\n")) + 1
+}
+
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
@@ -0,0 +1,225 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+package locale
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"en.json", FormatJSON},
+		{"locale/en.YML", FormatYAML},
+		{"en.yaml", FormatYAML},
+		{"en.ini", FormatINI},
+		{"en.locale", FormatINI},
+		{"messages.properties", FormatProperties},
+		{"ru.po", FormatPO},
+		{"template.pot", FormatPO},
+		{"values/strings.xml", FormatAndroid},
+		{"Localizable.strings", FormatStrings},
+		{"ru.xliff", FormatXLIFF},
+		{"ru.xlf", FormatXLIFF},
+		{"en.csv", ""},
+		{"README", ""},
+	}
+
+	for _, test := range tests {
+		got := DetectFormat(test.name)
+		if got != test.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []Entry
+	}{
+		{
+			name:   "json nested",
+			format: FormatJSON,
+			data:   "{\n  \"title\": \"Hello\",\n  \"menu\": {\n    \"file\": \"File\"\n  }\n}\n",
+			want: []Entry{
+				{Key: "title", Value: "Hello", Line: 2},
+				{Key: "menu.file", Value: "File", Line: 4},
+			},
+		},
+		{
+			name:   "json with BOM",
+			format: FormatJSON,
+			data:   "\xEF\xBB\xBF{\"a\": \"b\"}",
+			want: []Entry{
+				{Key: "a", Value: "b", Line: 1},
+			},
+		},
+		{
+			name:   "yaml nested",
+			format: FormatYAML,
+			data:   "# comment\nen:\n  title: Hello\n  quoted: \"a: b\"\n  menu:\n    file: File\n",
+			want: []Entry{
+				{Key: "en.title", Value: "Hello", Line: 3},
+				{Key: "en.quoted", Value: "a: b", Line: 4},
+				{Key: "en.menu.file", Value: "File", Line: 6},
+			},
+		},
+		{
+			name:   "yaml comments",
+			format: FormatYAML,
+			data:   "msg: It's fine # comment\nquoted: 'a # b' # comment\ndouble: \"say \\\"hi\\\" # x\" # comment\nlist: ['a # b', c] # comment\nhash: a#b\n",
+			want: []Entry{
+				{Key: "msg", Value: "It's fine", Line: 1},
+				{Key: "quoted", Value: "a # b", Line: 2},
+				{Key: "double", Value: "say \"hi\" # x", Line: 3},
+				{Key: "list", Value: "['a # b', c]", Line: 4},
+				{Key: "hash", Value: "a#b", Line: 5},
+			},
+		},
+		{
+			name:   "ini sections",
+			format: FormatINI,
+			data:   "; comment\nname = App\n\n[menu]\nfile = \"File\"\nedit='Edit'\n",
+			want: []Entry{
+				{Key: "name", Value: "App", Line: 2},
+				{Key: "menu.file", Value: "File", Line: 5},
+				{Key: "menu.edit", Value: "Edit", Line: 6},
+			},
+		},
+		{
+			name:   "properties",
+			format: FormatProperties,
+			data:   "# comment\ntitle = Hello\nmulti = first \\\n    second\nunicode: \\u0041\\uD83D\\uDE00\n",
+			want: []Entry{
+				{Key: "title", Value: "Hello", Line: 2},
+				{Key: "multi", Value: "first second", Line: 3},
+				{Key: "unicode", Value: "A\U0001F600", Line: 5},
+			},
+		},
+		{
+			name:   "gettext po",
+			format: FormatPO,
+			data:   "msgid \"\"\nmsgstr \"Language: ru\\n\"\n\n#. comment\nmsgid \"Hello\"\nmsgstr \"Привет\"\n\nmsgctxt \"menu\"\nmsgid \"File\"\nmsgstr \"\"\n\"Файл\"\n",
+			want: []Entry{
+				{Key: "Hello", Source: "Hello", Value: "Привет", Line: 5},
+				{Key: "menu|File", Source: "File", Value: "Файл", Line: 8},
+			},
+		},
+		{
+			name:   "android",
+			format: FormatAndroid,
+			data:   "<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n  <string name=\"title\">Hello</string>\n  <string name=\"quote\">Don\\'t</string>\n</resources>\n",
+			want: []Entry{
+				{Key: "title", Value: "Hello", Line: 3},
+				{Key: "quote", Value: "Don't", Line: 4},
+			},
+		},
+		{
+			name:   "apple strings",
+			format: FormatStrings,
+			data:   "/* comment */\n\"title\" = \"Hello\";\n\n\"quote\" = \"Say \\\"hi\\\"\";\n",
+			want: []Entry{
+				{Key: "title", Value: "Hello", Line: 2},
+				{Key: "quote", Value: "Say \"hi\"", Line: 4},
+			},
+		},
+		{
+			name:   "xliff",
+			format: FormatXLIFF,
+			data:   "<?xml version=\"1.0\"?>\n<xliff version=\"1.2\">\n <file>\n  <body>\n   <trans-unit id=\"title\">\n    <source>Hello</source>\n    <target>Привет</target>\n   </trans-unit>\n  </body>\n </file>\n</xliff>\n",
+			want: []Entry{
+				{Key: "title", Source: "Hello", Value: "Привет", Line: 5},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.format, []byte(test.data))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %d entries, want %d: %+v", test.name, len(got), len(test.want), got)
+			continue
+		}
+
+		for i, want := range test.want {
+			entry := got[i]
+			if entry.Key != want.Key || entry.Value != want.Value || entry.Source != want.Source || entry.Line != want.Line {
+				t.Errorf("%s: entry %d = {%q %q %q line %d}, want {%q %q %q line %d}", test.name, i,
+					entry.Key, entry.Value, entry.Source, entry.Line,
+					want.Key, want.Value, want.Source, want.Line)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		line   int
+	}{
+		{"json unclosed object", FormatJSON, "{\n  \"a\": \"b\",\n  \"c\": \n", 4},
+		{"json bad value", FormatJSON, "{\n  \"a\": \"b\",\n  \"c\": tru\n}\n", 3},
+		{"yaml missing separator", FormatYAML, "a:\n  b: c\n  d\n", 3},
+		{"yaml unclosed quote", FormatYAML, "a: \"b\nc: d\n", 1},
+		{"ini unclosed section", FormatINI, "a = b\n[menu\n", 2},
+		{"ini missing separator", FormatINI, "a = b\n\nc\n", 3},
+		{"ini unbalanced quotes", FormatINI, "a = \"b\n", 1},
+		{"properties bad unicode escape", FormatProperties, "a = b\nc = \\u00zz\n", 2},
+		{"po unterminated string", FormatPO, "msgid \"a\"\nmsgstr \"b\n", 2},
+		{"po msgstr without msgid", FormatPO, "\nmsgstr \"b\"\n", 2},
+		{"android unclosed tag", FormatAndroid, "<resources>\n  <string name=\"a\">b\n</resources>\n", 3},
+		{"android missing name", FormatAndroid, "<resources>\n  <string>b</string>\n</resources>\n", 2},
+		{"strings missing semicolon", FormatStrings, "\"a\" = \"b\";\n\"c\" = \"d\"\n", 3},
+		{"strings missing value", FormatStrings, "\"a\" = \"b\";\n\"c\";\n", 2},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.format, []byte(test.data))
+		if err == nil {
+			t.Errorf("%s: expected error", test.name)
+			continue
+		}
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: got %T error, want *SyntaxError: %s", test.name, err, err)
+			continue
+		}
+
+		if syntaxErr.Line != test.line {
+			t.Errorf("%s: error on line %d, want %d: %s", test.name, syntaxErr.Line, test.line, err)
+		}
+	}
+
+	_, err := Parse("csv", []byte("a,b"))
+	if err == nil {
+		t.Error("unknown format: expected error")
+	}
+}
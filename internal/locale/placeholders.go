@@ -0,0 +1,187 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// printf style: %s, %d, %1$s, %.2f, %@; Python: %(name)s; Ruby: %{name}
+	printfPlaceholder = regexp.MustCompile(`%(?:\([\w.-]+\)[sdifr]|\{[\w.-]+\}|[0-9]+\$[-+#0]*[0-9]*(?:\.[0-9]+)?[sdifuxXoeEgGcpb@]|[-+#0]*[0-9]*(?:\.[0-9]+)?(?:l|ll|h|hh|z)?[sdifuxXoeEgGcpb@])`)
+
+	placeholderName = regexp.MustCompile(`^[\w.-]+$`)
+)
+
+// Placeholders returns sorted placeholders of the string: printf style (%s, %1$d),
+// named (%(name)s, %{name}), {name}, {0}, {{name}} and ICU arguments ({count, plural, ...}).
+func Placeholders(s string) []string {
+	var result []string
+
+	// Remove escaped percent signs
+	text := strings.Replace(s, "%%", "", -1)
+
+	for _, match := range printfPlaceholder.FindAllString(text, -1) {
+		result = append(result, match)
+	}
+	text = printfPlaceholder.ReplaceAllString(text, "")
+
+	// Brace placeholders
+	for i := 0; i < len(text); i++ {
+		if text[i] != '{' {
+			continue
+		}
+
+		// {{name}}
+		if strings.HasPrefix(text[i:], "{{") {
+			end := strings.Index(text[i+2:], "}}")
+			if end != -1 {
+				name := strings.TrimSpace(text[i+2 : i+2+end])
+				if placeholderName.MatchString(name) {
+					result = append(result, "{{"+name+"}}")
+				}
+				i += end + 3
+				continue
+			}
+		}
+
+		end := matchingBrace(text, i)
+		if end == -1 {
+			continue
+		}
+
+		// {name} or ICU argument {name, type, ...}
+		content := text[i+1 : end]
+		name := strings.TrimSpace(strings.SplitN(content, ",", 2)[0])
+		if placeholderName.MatchString(name) {
+			result = append(result, "{"+name+"}")
+		}
+
+		i = end
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// matchingBrace returns the index of the brace that closes the brace at the start position.
+func matchingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// ComparePlaceholders returns placeholders which are missing in the translation
+// and placeholders which are not present in the source.
+func ComparePlaceholders(source string, translation string) ([]string, []string) {
+	count := make(map[string]int)
+	for _, p := range Placeholders(source) {
+		count[p]++
+	}
+
+	for _, p := range Placeholders(translation) {
+		count[p]--
+	}
+
+	var missing, extra []string
+	for p, n := range count {
+		for ; n > 0; n-- {
+			missing = append(missing, p)
+		}
+
+		for ; n < 0; n++ {
+			extra = append(extra, p)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+// CheckICU checks braces balance and plural/select arguments of the ICU message.
+func CheckICU(s string) error {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return errors.New("unexpected '}'")
+			}
+		}
+	}
+
+	if depth != 0 {
+		return errors.New("unbalanced braces")
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '{' {
+			continue
+		}
+
+		end := matchingBrace(s, i)
+		parts := strings.SplitN(s[i+1:end], ",", 3)
+		if len(parts) == 3 {
+			argType := strings.TrimSpace(parts[1])
+			if argType == "plural" || argType == "select" || argType == "selectordinal" {
+				if !strings.Contains(parts[2], "other") || !strings.Contains(parts[2], "{") {
+					return errors.New(argType + " argument '" + strings.TrimSpace(parts[0]) + "' must have 'other' case")
+				}
+
+				// Check nested messages of the cases
+				cases := parts[2]
+				for j := 0; j < len(cases); j++ {
+					if cases[j] != '{' {
+						continue
+					}
+
+					caseEnd := matchingBrace(cases, j)
+					err := CheckICU(cases[j+1 : caseEnd])
+					if err != nil {
+						return err
+					}
+
+					j = caseEnd
+				}
+			}
+		}
+
+		i = end
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+package locale
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"No placeholders", nil},
+		{"Hello, %s!", []string{"%s"}},
+		{"%d of %d", []string{"%d", "%d"}},
+		{"100%% done", nil},
+		{"%1$s and %2$d", []string{"%1$s", "%2$d"}},
+		{"%.2f MB", []string{"%.2f"}},
+		{"%@ says hi", []string{"%@"}},
+		{"Hello, %(name)s", []string{"%(name)s"}},
+		{"Hello, %{name}", []string{"%{name}"}},
+		{"Hello, {name}", []string{"{name}"}},
+		{"{1} before {0}", []string{"{0}", "{1}"}},
+		{"Hello, {{ user.name }}", []string{"{{user.name}}"}},
+		{"{count, plural, one {# file} other {# files}}", []string{"{count}"}},
+		{"Empty {} braces", nil},
+		{"Unclosed {name", nil},
+	}
+
+	for _, test := range tests {
+		got := Placeholders(test.s)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Placeholders(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestComparePlaceholders(t *testing.T) {
+	tests := []struct {
+		source      string
+		translation string
+		missing     []string
+		extra       []string
+	}{
+		{"Hello, %s!", "Привет, %s!", nil, nil},
+		{"%1$s and %2$s", "%2$s и %1$s", nil, nil},
+		{"Hello, {name}", "Привет", []string{"{name}"}, nil},
+		{"Hello", "Привет, %s", nil, []string{"%s"}},
+		{"%d of %d", "%d из", []string{"%d"}, nil},
+		{"{user} has {count}", "{usr} имеет {count}", []string{"{user}"}, []string{"{usr}"}},
+	}
+
+	for _, test := range tests {
+		missing, extra := ComparePlaceholders(test.source, test.translation)
+		if !reflect.DeepEqual(missing, test.missing) || !reflect.DeepEqual(extra, test.extra) {
+			t.Errorf("ComparePlaceholders(%q, %q) = %q, %q, want %q, %q",
+				test.source, test.translation, missing, extra, test.missing, test.extra)
+		}
+	}
+}
+
+func TestCheckICU(t *testing.T) {
+	tests := []struct {
+		s     string
+		valid bool
+	}{
+		{"Hello, {name}", true},
+		{"{count, plural, one {# file} other {# files}}", true},
+		{"{gender, select, male {He} female {She} other {They}}", true},
+		{"{count, plural, =0 {none} other {{n, plural, one {# x} other {# xs}}}}", true},
+		{"{count, plural, one {# file}}", false},
+		{"{count, plural, one {# file} other {# files}", false},
+		{"Hello}", false},
+		{"{a, select, x {1} other {{b, plural, one {y}}}}", false},
+	}
+
+	for _, test := range tests {
+		err := CheckICU(test.s)
+		if (err == nil) != test.valid {
+			t.Errorf("CheckICU(%q) = %v, want valid %v", test.s, err, test.valid)
+		}
+	}
+}
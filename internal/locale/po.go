@@ -0,0 +1,256 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"strconv"
+	"strings"
+)
+
+type poMessage struct {
+	ctxt     string
+	id       *string
+	idPlural string
+	str      map[int]*string
+	comment  []string
+	line     int
+
+	// Field to which continuation strings are appended
+	last *string
+}
+
+// Read more: https://www.gnu.org/software/gettext/manual/html_node/PO-Files.html
+func parsePO(data []byte) ([]Entry, error) {
+	var entries []Entry
+
+	msg := &poMessage{str: make(map[int]*string)}
+
+	flush := func() error {
+		if msg.id == nil && len(msg.str) == 0 && msg.ctxt == "" {
+			// Comments are kept for the next message
+			msg.last = nil
+			return nil
+		}
+
+		if msg.id == nil {
+			return newSyntaxError(msg.line, "missing msgid")
+		}
+
+		if len(msg.str) == 0 {
+			return newSyntaxError(msg.line, "missing msgstr")
+		}
+
+		for n := range msg.str {
+			if n < 0 || n >= len(msg.str) {
+				return newSyntaxError(msg.line, "bad plural form index: "+strconv.Itoa(n))
+			}
+		}
+
+		// Skip header
+		if *msg.id != "" || msg.ctxt != "" {
+			key := *msg.id
+			if msg.ctxt != "" {
+				key = msg.ctxt + "|" + *msg.id
+			}
+
+			entries = append(entries, Entry{
+				Key:     key,
+				Value:   *msg.str[0],
+				Source:  *msg.id,
+				Comment: strings.Join(msg.comment, "\n"),
+				Line:    msg.line,
+			})
+
+			for n := 1; n < len(msg.str); n++ {
+				entries = append(entries, Entry{
+					Key:    key + "[" + strconv.Itoa(n) + "]",
+					Value:  *msg.str[n],
+					Source: msg.idPlural,
+					Line:   msg.line,
+				})
+			}
+		}
+
+		msg = &poMessage{str: make(map[int]*string)}
+		return nil
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "":
+			err := flush()
+			if err != nil {
+				return nil, err
+			}
+
+		case strings.HasPrefix(line, "#~"):
+			// Obsolete message
+
+		case line[0] == '#':
+			if len(msg.str) != 0 {
+				err := flush()
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			// Translator and extracted comments
+			if line == "#" || strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "#.") {
+				msg.comment = append(msg.comment, strings.TrimSpace(strings.TrimPrefix(line[1:], ".")))
+			}
+
+		case line[0] == '"':
+			if msg.last == nil {
+				return nil, newSyntaxError(lineNum, "string without keyword")
+			}
+
+			val, err := unquoteC(line, lineNum)
+			if err != nil {
+				return nil, err
+			}
+			*msg.last += val
+
+		default:
+			sep := strings.IndexAny(line, " \t")
+			if sep == -1 {
+				return nil, newSyntaxError(lineNum, "expected keyword and string: "+line)
+			}
+
+			keyword := line[:sep]
+			val, err := unquoteC(strings.TrimSpace(line[sep:]), lineNum)
+			if err != nil {
+				return nil, err
+			}
+
+			// Next message starts
+			if (keyword == "msgctxt" || keyword == "msgid") && len(msg.str) != 0 {
+				err = flush()
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if msg.line == 0 {
+				msg.line = lineNum
+			}
+
+			switch {
+			case keyword == "msgctxt":
+				msg.ctxt = val
+				msg.last = &msg.ctxt
+
+			case keyword == "msgid":
+				if msg.id != nil {
+					return nil, newSyntaxError(lineNum, "duplicate msgid")
+				}
+				msg.id = &val
+				msg.last = msg.id
+
+			case keyword == "msgid_plural":
+				msg.idPlural = val
+				msg.last = &msg.idPlural
+
+			case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+				if msg.id == nil {
+					return nil, newSyntaxError(lineNum, "msgstr without msgid")
+				}
+
+				n := 0
+				if keyword != "msgstr" {
+					if !strings.HasSuffix(keyword, "]") {
+						return nil, newSyntaxError(lineNum, "bad keyword: "+keyword)
+					}
+
+					n, err = strconv.Atoi(keyword[len("msgstr[") : len(keyword)-1])
+					if err != nil {
+						return nil, newSyntaxError(lineNum, "bad plural form index: "+keyword)
+					}
+				}
+
+				if _, ok := msg.str[n]; ok {
+					return nil, newSyntaxError(lineNum, "duplicate "+keyword)
+				}
+
+				msg.str[n] = &val
+				msg.last = &val
+
+			default:
+				return nil, newSyntaxError(lineNum, "unknown keyword: "+keyword)
+			}
+		}
+	}
+
+	err := flush()
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// unquoteC unquotes a C-style double quoted string.
+func unquoteC(s string, line int) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", newSyntaxError(line, "expected quoted string: "+s)
+	}
+	s = s[1 : len(s)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return "", newSyntaxError(line, "unescaped quote in string")
+
+		case '\\':
+			i++
+			if i >= len(s) {
+				return "", newSyntaxError(line, "unterminated escape sequence")
+			}
+
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'a':
+				b.WriteByte('\a')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'v':
+				b.WriteByte('\v')
+			case '\\', '"', '\'', '?':
+				b.WriteByte(s[i])
+			default:
+				return "", newSyntaxError(line, "unknown escape sequence: \\"+string(s[i]))
+			}
+
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
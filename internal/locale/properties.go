@@ -0,0 +1,159 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Read more: https://docs.oracle.com/javase/8/docs/api/java/util/Properties.html#load-java.io.Reader-
+func parseProperties(data []byte) ([]Entry, error) {
+	var entries []Entry
+	var comment []string
+
+	lines := strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+
+		if line == "" {
+			comment = nil
+			continue
+		}
+
+		if line[0] == '#' || line[0] == '!' {
+			comment = append(comment, strings.TrimSpace(line[1:]))
+			continue
+		}
+
+		// Join continuation lines
+		for endsWithBackslash(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		// Split key and value
+		sep := len(line)
+		for j := 0; j < len(line); j++ {
+			if line[j] == '\\' {
+				j++
+				continue
+			}
+
+			if line[j] == '=' || line[j] == ':' || line[j] == ' ' || line[j] == '\t' || line[j] == '\f' {
+				sep = j
+				break
+			}
+		}
+
+		rawKey := line[:sep]
+		rawVal := strings.TrimLeft(line[sep:], " \t\f")
+		if rawVal != "" && (rawVal[0] == '=' || rawVal[0] == ':') {
+			rawVal = strings.TrimLeft(rawVal[1:], " \t\f")
+		}
+
+		key, err := unescapeProperties(rawKey, lineNum)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := unescapeProperties(rawVal, lineNum)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{
+			Key:     key,
+			Value:   val,
+			Comment: strings.Join(comment, "\n"),
+			Line:    lineNum,
+		})
+		comment = nil
+	}
+
+	return entries, nil
+}
+
+func endsWithBackslash(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+func unescapeProperties(s string, line int) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		if i >= len(s) {
+			break
+		}
+
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", newSyntaxError(line, "malformed \\uXXXX escape")
+			}
+
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", newSyntaxError(line, "malformed \\uXXXX escape: \\u"+s[i+1:i+5])
+			}
+
+			i += 4
+
+			// UTF-16 surrogate pair
+			r := rune(code)
+			if utf16.IsSurrogate(r) && i+7 <= len(s) && s[i+1:i+3] == "\\u" {
+				low, err := strconv.ParseUint(s[i+3:i+7], 16, 16)
+				if err == nil {
+					if pair := utf16.DecodeRune(r, rune(low)); pair != unicode.ReplacementChar {
+						r = pair
+						i += 6
+					}
+				}
+			}
+
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,219 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type stringsScanner struct {
+	data    string
+	pos     int
+	line    int
+	comment []string
+}
+
+// skip skips whitespace and comments, collecting the comments text.
+func (sc *stringsScanner) skip() error {
+	for sc.pos < len(sc.data) {
+		switch {
+		case sc.data[sc.pos] == '\n':
+			sc.line++
+			sc.pos++
+
+		case strings.IndexByte(" \t\r", sc.data[sc.pos]) != -1:
+			sc.pos++
+
+		case strings.HasPrefix(sc.data[sc.pos:], "//"):
+			end := strings.IndexByte(sc.data[sc.pos:], '\n')
+			if end == -1 {
+				end = len(sc.data) - sc.pos
+			}
+			sc.comment = append(sc.comment, strings.TrimSpace(sc.data[sc.pos+2:sc.pos+end]))
+			sc.pos += end
+
+		case strings.HasPrefix(sc.data[sc.pos:], "/*"):
+			end := strings.Index(sc.data[sc.pos+2:], "*/")
+			if end == -1 {
+				return newSyntaxError(sc.line, "unterminated comment")
+			}
+			text := sc.data[sc.pos+2 : sc.pos+2+end]
+			sc.comment = append(sc.comment, strings.TrimSpace(text))
+			sc.line += strings.Count(text, "\n")
+			sc.pos += end + 4
+
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (sc *stringsScanner) readString() (string, error) {
+	// Unquoted identifier
+	if sc.data[sc.pos] != '"' {
+		start := sc.pos
+		for sc.pos < len(sc.data) && strings.IndexByte(" \t\r\n=;\"", sc.data[sc.pos]) == -1 {
+			sc.pos++
+		}
+
+		if start == sc.pos {
+			return "", newSyntaxError(sc.line, "expected string, got '"+string(sc.data[sc.pos])+"'")
+		}
+
+		return sc.data[start:sc.pos], nil
+	}
+
+	startLine := sc.line
+	var b strings.Builder
+
+	sc.pos++
+	for sc.pos < len(sc.data) {
+		c := sc.data[sc.pos]
+		switch c {
+		case '"':
+			sc.pos++
+			return b.String(), nil
+
+		case '\n':
+			sc.line++
+			b.WriteByte(c)
+			sc.pos++
+
+		case '\\':
+			sc.pos++
+			if sc.pos >= len(sc.data) {
+				return "", newSyntaxError(sc.line, "unterminated escape sequence")
+			}
+
+			switch sc.data[sc.pos] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '0':
+				b.WriteByte(0)
+			case 'U', 'u':
+				if sc.pos+5 > len(sc.data) {
+					return "", newSyntaxError(sc.line, "malformed \\UXXXX escape")
+				}
+
+				code, err := strconv.ParseUint(sc.data[sc.pos+1:sc.pos+5], 16, 16)
+				if err != nil {
+					return "", newSyntaxError(sc.line, "malformed \\UXXXX escape: "+sc.data[sc.pos-1:sc.pos+5])
+				}
+
+				b.WriteRune(rune(code))
+				sc.pos += 4
+			default:
+				b.WriteByte(sc.data[sc.pos])
+			}
+			sc.pos++
+
+		default:
+			_, size := utf8.DecodeRuneInString(sc.data[sc.pos:])
+			b.WriteString(sc.data[sc.pos : sc.pos+size])
+			sc.pos += size
+		}
+	}
+
+	return "", newSyntaxError(startLine, "unterminated string")
+}
+
+func (sc *stringsScanner) expect(c byte) error {
+	err := sc.skip()
+	if err != nil {
+		return err
+	}
+
+	if sc.pos >= len(sc.data) {
+		return newSyntaxError(sc.line, "expected '"+string(c)+"', got end of file")
+	}
+
+	if sc.data[sc.pos] != c {
+		return newSyntaxError(sc.line, "expected '"+string(c)+"', got '"+string(sc.data[sc.pos])+"'")
+	}
+
+	sc.pos++
+	return nil
+}
+
+// Read more: https://developer.apple.com/library/archive/documentation/Cocoa/Conceptual/LoadingResources/Strings/Strings.html
+func parseStrings(data []byte) ([]Entry, error) {
+	var entries []Entry
+
+	sc := stringsScanner{data: string(data), line: 1}
+	for {
+		sc.comment = nil
+		err := sc.skip()
+		if err != nil {
+			return nil, err
+		}
+
+		if sc.pos >= len(sc.data) {
+			break
+		}
+
+		line := sc.line
+		comment := strings.Join(sc.comment, "\n")
+
+		key, err := sc.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		err = sc.expect('=')
+		if err != nil {
+			return nil, err
+		}
+
+		err = sc.skip()
+		if err != nil {
+			return nil, err
+		}
+
+		if sc.pos >= len(sc.data) {
+			return nil, newSyntaxError(sc.line, "expected value, got end of file")
+		}
+
+		val, err := sc.readString()
+		if err != nil {
+			return nil, err
+		}
+
+		err = sc.expect(';')
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, Entry{
+			Key:     key,
+			Value:   val,
+			Comment: comment,
+			Line:    line,
+		})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,375 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// CanWrite returns true if entries can be written in the file format.
+// Bilingual formats (gettext PO, XLIFF) can only be parsed.
+func CanWrite(format string) bool {
+	switch format {
+	case FormatJSON, FormatYAML, FormatINI, FormatProperties, FormatStrings, FormatAndroid:
+		return true
+	}
+
+	return false
+}
+
+// Write formats entries in the file format.
+// Entry keys are expected in the form returned by Parse, for example nested JSON keys are joined with '.'.
+func Write(format string, entries []Entry) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return writeJSON(entries)
+	case FormatYAML:
+		return writeYAML(entries)
+	case FormatINI:
+		return writeINI(entries)
+	case FormatProperties:
+		return writeProperties(entries), nil
+	case FormatStrings:
+		return writeStrings(entries), nil
+	case FormatAndroid:
+		return writeAndroid(entries), nil
+	}
+
+	return nil, errors.New("writing is not supported for file format: " + format)
+}
+
+// keyNode is a node of the nested keys tree in the file order.
+type keyNode struct {
+	name     string
+	value    *string
+	children []*keyNode
+	index    map[string]*keyNode
+}
+
+func buildKeyTree(entries []Entry) (*keyNode, error) {
+	root := &keyNode{index: make(map[string]*keyNode)}
+
+	for _, entry := range entries {
+		node := root
+		parts := strings.Split(entry.Key, ".")
+		for i, part := range parts {
+			if node.value != nil {
+				return nil, errors.New("key conflicts with nested keys: " + entry.Key)
+			}
+
+			child, ok := node.index[part]
+			if !ok {
+				child = &keyNode{name: part, index: make(map[string]*keyNode)}
+				node.index[part] = child
+				node.children = append(node.children, child)
+			}
+			node = child
+
+			if i == len(parts)-1 {
+				if len(node.children) != 0 || node.value != nil {
+					return nil, errors.New("duplicate or conflicting key: " + entry.Key)
+				}
+
+				val := entry.Value
+				node.value = &val
+			}
+		}
+	}
+
+	return root, nil
+}
+
+// jsonString returns the JSON string literal without HTML escaping.
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func writeJSON(entries []Entry) ([]byte, error) {
+	root, err := buildKeyTree(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	var write func(node *keyNode, indent string)
+	write = func(node *keyNode, indent string) {
+		if node.value != nil {
+			b.WriteString(jsonString(*node.value))
+			return
+		}
+
+		if len(node.children) == 0 {
+			b.WriteString("{}")
+			return
+		}
+
+		b.WriteString("{\n")
+		for i, child := range node.children {
+			b.WriteString(indent + "  " + jsonString(child.name) + ": ")
+			write(child, indent+"  ")
+			if i != len(node.children)-1 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteString(indent + "}")
+	}
+
+	write(root, "")
+	b.WriteByte('\n')
+
+	return []byte(b.String()), nil
+}
+
+// yamlKey quotes the key if it is not a plain YAML scalar.
+func yamlKey(key string) string {
+	if key == "" || strings.ContainsAny(key, ":#{}[],&*!|>'\"%@`\\ \t") || strings.ContainsAny(key[:1], "-?") {
+		return jsonString(key)
+	}
+
+	return key
+}
+
+func writeYAML(entries []Entry) ([]byte, error) {
+	root, err := buildKeyTree(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	var write func(node *keyNode, indent string)
+	write = func(node *keyNode, indent string) {
+		for _, child := range node.children {
+			b.WriteString(indent + yamlKey(child.name) + ":")
+			if child.value != nil {
+				// JSON string is a valid YAML double-quoted scalar
+				b.WriteString(" " + jsonString(*child.value) + "\n")
+				continue
+			}
+
+			if len(child.children) == 0 {
+				b.WriteString(" {}\n")
+				continue
+			}
+
+			b.WriteByte('\n')
+			write(child, indent+"  ")
+		}
+	}
+
+	write(root, "")
+
+	return []byte(b.String()), nil
+}
+
+func writeINI(entries []Entry) ([]byte, error) {
+	var sections []string
+	bySection := make(map[string][]string)
+
+	for _, entry := range entries {
+		section, key := "", entry.Key
+		if i := strings.LastIndexByte(entry.Key, '.'); i != -1 {
+			section, key = entry.Key[:i], entry.Key[i+1:]
+		}
+
+		if strings.ContainsAny(entry.Value, "\r\n") {
+			return nil, errors.New("multi-line values are not supported in INI: " + entry.Key)
+		}
+
+		val := entry.Value
+		if val != strings.TrimSpace(val) || strings.HasPrefix(val, "\"") || strings.HasPrefix(val, "'") {
+			val = "\"" + val + "\""
+		}
+
+		if _, ok := bySection[section]; !ok && section != "" {
+			sections = append(sections, section)
+		}
+		bySection[section] = append(bySection[section], key+" = "+val)
+	}
+
+	var b strings.Builder
+	for _, line := range bySection[""] {
+		b.WriteString(line + "\n")
+	}
+
+	for _, section := range sections {
+		if b.Len() != 0 {
+			b.WriteByte('\n')
+		}
+
+		b.WriteString("[" + section + "]\n")
+		for _, line := range bySection[section] {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	return []byte(b.String()), nil
+}
+
+func escapeProperties(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString("\\\\")
+		case r == '\n':
+			b.WriteString("\\n")
+		case r == '\r':
+			b.WriteString("\\r")
+		case r == '\t':
+			b.WriteString("\\t")
+		case r == '\f':
+			b.WriteString("\\f")
+		case r == ' ' && (isKey || i == 0):
+			b.WriteString("\\ ")
+		case isKey && (r == '=' || r == ':'):
+			b.WriteString("\\" + string(r))
+		case (r == '#' || r == '!') && i == 0:
+			b.WriteString("\\" + string(r))
+		case r > 0x7e:
+			// Encode as UTF-16 code units
+			for _, u := range utf16.Encode([]rune{r}) {
+				b.WriteString("\\u" + strings.ToUpper(strconv.FormatInt(int64(u)+0x10000, 16)[1:]))
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func writeProperties(entries []Entry) []byte {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString(escapeProperties(entry.Key, true) + "=" + escapeProperties(entry.Value, false) + "\n")
+	}
+
+	return []byte(b.String())
+}
+
+func escapeStrings(s string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		"\"", "\\\"",
+		"\n", "\\n",
+		"\r", "\\r",
+		"\t", "\\t",
+	).Replace(s)
+}
+
+func writeStrings(entries []Entry) []byte {
+	var b strings.Builder
+	for _, entry := range entries {
+		b.WriteString("\"" + escapeStrings(entry.Key) + "\" = \"" + escapeStrings(entry.Value) + "\";\n")
+	}
+
+	return []byte(b.String())
+}
+
+func escapeAndroid(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+
+	val := strings.NewReplacer(
+		"\\", "\\\\",
+		"'", "\\'",
+		"&#34;", "\\\"",
+		"&#39;", "\\'",
+		"&#xA;", "\\n",
+		"&#x9;", "\\t",
+	).Replace(buf.String())
+
+	if strings.HasPrefix(val, "@") || strings.HasPrefix(val, "?") {
+		val = "\\" + val
+	}
+
+	return val
+}
+
+// splitItemKey splits Android array and plurals item keys: "name[quantity]".
+func splitItemKey(key string) (string, string, bool) {
+	i := strings.LastIndexByte(key, '[')
+	if i <= 0 || !strings.HasSuffix(key, "]") {
+		return "", "", false
+	}
+
+	return key[:i], key[i+1 : len(key)-1], true
+}
+
+func writeAndroid(entries []Entry) []byte {
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n")
+
+	attr := func(s string) string {
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(s))
+		return buf.String()
+	}
+
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+
+		name, item, ok := splitItemKey(entry.Key)
+		if !ok {
+			b.WriteString("  <string name=\"" + attr(entry.Key) + "\">" + escapeAndroid(entry.Value) + "</string>\n")
+			continue
+		}
+
+		// Collect items of the same array or plurals
+		_, err := strconv.Atoi(item)
+		isArray := err == nil
+
+		tag := "plurals"
+		if isArray {
+			tag = "string-array"
+		}
+
+		b.WriteString("  <" + tag + " name=\"" + attr(name) + "\">\n")
+		for ; i < len(entries); i++ {
+			itemName, item, ok := splitItemKey(entries[i].Key)
+			if !ok || itemName != name {
+				break
+			}
+
+			if isArray {
+				b.WriteString("    <item>" + escapeAndroid(entries[i].Value) + "</item>\n")
+			} else {
+				b.WriteString("    <item quantity=\"" + attr(item) + "\">" + escapeAndroid(entries[i].Value) + "</item>\n")
+			}
+		}
+		i--
+		b.WriteString("  </" + tag + ">\n")
+	}
+
+	b.WriteString("</resources>\n")
+
+	return []byte(b.String())
+}
@@ -0,0 +1,337 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type xmlReader struct {
+	data []byte
+	dec  *xml.Decoder
+}
+
+func newXMLReader(data []byte) *xmlReader {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = true
+
+	return &xmlReader{
+		data: data,
+		dec:  dec,
+	}
+}
+
+// line returns the line on which the last read token ends.
+func (r *xmlReader) line() int {
+	return lineOf(r.data, r.dec.InputOffset())
+}
+
+func (r *xmlReader) error(err error) error {
+	var xmlErr *xml.SyntaxError
+	if errors.As(err, &xmlErr) {
+		return newSyntaxError(xmlErr.Line, xmlErr.Msg)
+	}
+
+	return newSyntaxError(r.line(), err.Error())
+}
+
+// token returns the next token or io.EOF.
+func (r *xmlReader) token() (xml.Token, error) {
+	tok, err := r.dec.Token()
+	if err == io.EOF {
+		return nil, err
+	}
+
+	if err != nil {
+		return nil, r.error(err)
+	}
+
+	return tok, nil
+}
+
+// innerText reads the element content up to its end and returns the text of all nested elements.
+func (r *xmlReader) innerText() (string, error) {
+	var b strings.Builder
+
+	for depth := 1; depth > 0; {
+		tok, err := r.token()
+		if err == io.EOF {
+			return "", newSyntaxError(r.line(), "unexpected end of file")
+		}
+		if err != nil {
+			return "", err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			b.Write(tok)
+		}
+	}
+
+	return b.String(), nil
+}
+
+func xmlAttr(elem xml.StartElement, name string) string {
+	for _, attr := range elem.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// Read more: https://developer.android.com/guide/topics/resources/string-resource
+func parseAndroid(data []byte) ([]Entry, error) {
+	var entries []Entry
+	var comment []string
+
+	r := newXMLReader(data)
+	isResources := false
+
+	for depth := 0; ; {
+		tok, err := r.token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.Comment:
+			comment = append(comment, strings.TrimSpace(string(tok)))
+
+		case xml.EndElement:
+			depth--
+
+		case xml.StartElement:
+			line := r.line()
+			depth++
+
+			if depth == 1 {
+				isResources = tok.Name.Local == "resources"
+				continue
+			}
+
+			// Only check that the file is well-formed
+			if !isResources || depth != 2 {
+				continue
+			}
+
+			name := xmlAttr(tok, "name")
+			switch tok.Name.Local {
+			case "string":
+				if name == "" {
+					return nil, newSyntaxError(line, "string without name")
+				}
+
+				val, err := r.innerText()
+				if err != nil {
+					return nil, err
+				}
+				depth--
+
+				val, err = unescapeAndroid(val, line)
+				if err != nil {
+					return nil, err
+				}
+
+				entries = append(entries, Entry{Key: name, Value: val, Comment: strings.Join(comment, "\n"), Line: line})
+
+			case "string-array", "plurals":
+				if name == "" {
+					return nil, newSyntaxError(line, tok.Name.Local+" without name")
+				}
+
+				items, err := r.androidItems(name, tok.Name.Local == "plurals")
+				if err != nil {
+					return nil, err
+				}
+				depth--
+
+				if len(items) != 0 {
+					items[0].Comment = strings.Join(comment, "\n")
+				}
+				entries = append(entries, items...)
+			}
+
+			comment = nil
+		}
+	}
+
+	return entries, nil
+}
+
+func (r *xmlReader) androidItems(name string, plurals bool) ([]Entry, error) {
+	var entries []Entry
+
+	for i := 0; ; {
+		tok, err := r.token()
+		if err == io.EOF {
+			return nil, newSyntaxError(r.line(), "unexpected end of file")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.EndElement:
+			return entries, nil
+
+		case xml.StartElement:
+			line := r.line()
+			if tok.Name.Local != "item" {
+				return nil, newSyntaxError(line, "unexpected element: "+tok.Name.Local)
+			}
+
+			key := name + "[" + xmlAttr(tok, "quantity") + "]"
+			if !plurals {
+				key = name + "[" + strconv.Itoa(i) + "]"
+				i++
+			} else if xmlAttr(tok, "quantity") == "" {
+				return nil, newSyntaxError(line, "plural item without quantity")
+			}
+
+			val, err := r.innerText()
+			if err != nil {
+				return nil, err
+			}
+
+			val, err = unescapeAndroid(val, line)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, Entry{Key: key, Value: val, Line: line})
+		}
+	}
+}
+
+func unescapeAndroid(s string, line int) (string, error) {
+	// Quoted string
+	trimmed := strings.TrimSpace(s)
+	if len(trimmed) >= 2 && trimmed[0] == '"' && trimmed[len(trimmed)-1] == '"' {
+		return trimmed[1 : len(trimmed)-1], nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return "", newSyntaxError(line, "apostrophe not preceded by \\")
+
+		case '"':
+			return "", newSyntaxError(line, "unescaped quote, use \\\" or quote the whole string")
+
+		case '\\':
+			i++
+			if i >= len(s) {
+				return "", newSyntaxError(line, "unterminated escape sequence")
+			}
+
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				b.WriteString("\\u")
+			default:
+				b.WriteByte(s[i])
+			}
+
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String(), nil
+}
+
+// Read more: https://docs.oasis-open.org/xliff/v1.2/os/xliff-core.html
+// and https://docs.oasis-open.org/xliff/xliff-core/v2.0/xliff-core-v2.0.html
+func parseXLIFF(data []byte) ([]Entry, error) {
+	var entries []Entry
+	var unit *Entry
+
+	r := newXMLReader(data)
+	for {
+		tok, err := r.token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "trans-unit", "unit":
+				key := xmlAttr(tok, "resname")
+				if key == "" {
+					key = xmlAttr(tok, "id")
+				}
+
+				if key == "" {
+					return nil, newSyntaxError(r.line(), tok.Name.Local+" without id")
+				}
+
+				unit = &Entry{Key: key, Line: r.line()}
+
+			case "source", "target", "note":
+				if unit == nil {
+					continue
+				}
+
+				text, err := r.innerText()
+				if err != nil {
+					return nil, err
+				}
+
+				switch tok.Name.Local {
+				case "source":
+					unit.Source += text
+				case "target":
+					unit.Value += text
+				case "note":
+					unit.Comment = strings.TrimSpace(unit.Comment + "\n" + text)
+				}
+			}
+
+		case xml.EndElement:
+			if (tok.Name.Local == "trans-unit" || tok.Name.Local == "unit") && unit != nil {
+				entries = append(entries, *unit)
+				unit = nil
+			}
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,453 @@
+// Copyright (C) 2022-2023 Leonid Maslakov.
+
+// This file is part of drone-crowdin-v2.
+
+// drone-crowdin-v2 is free software: you can redistribute it
+// and/or modify it under the terms of the
+// GNU Affero Public License as published by the
+// Free Software Foundation, either version 3 of the License,
+// or (at your option) any later version.
+
+// drone-crowdin-v2 is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of MERCHANTABILITY
+// or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU Affero Public License for more details.
+
+// You should have received a copy of the GNU Affero Public License along with drone-crowdin-v2.
+// If not, see <https://www.gnu.org/licenses/>.
+
+package locale
+
+import (
+	"strconv"
+	"strings"
+)
+
+// YAML parser supports the subset of YAML used in translation files:
+// nested mappings, sequences, plain, quoted and block scalars.
+
+type yamlLevel struct {
+	indent int
+	prefix string
+	seq    int
+}
+
+type yamlParser struct {
+	lines   []string
+	pos     int
+	stack   []yamlLevel
+	entries []Entry
+	comment []string
+
+	// Last plain scalar, it can be continued on the next lines
+	lastPlain       int
+	lastPlainIndent int
+}
+
+func parseYAML(data []byte) ([]Entry, error) {
+	p := yamlParser{
+		lines:     strings.Split(strings.Replace(string(data), "\r\n", "\n", -1), "\n"),
+		stack:     []yamlLevel{{indent: -1}},
+		lastPlain: -1,
+	}
+
+	for ; p.pos < len(p.lines); p.pos++ {
+		raw := p.lines[p.pos]
+		line := p.pos + 1
+		trimmed := strings.TrimSpace(raw)
+
+		if trimmed == "" {
+			p.comment = nil
+			continue
+		}
+
+		if trimmed[0] == '#' {
+			p.comment = append(p.comment, strings.TrimSpace(trimmed[1:]))
+			continue
+		}
+
+		// Document markers and directives
+		if trimmed == "---" || trimmed == "..." || trimmed[0] == '%' {
+			continue
+		}
+
+		indentStr := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]
+		if strings.Contains(indentStr, "\t") {
+			return nil, newSyntaxError(line, "tabs are not allowed in indentation")
+		}
+		indent := len(indentStr)
+
+		// Continuation of a multi-line plain scalar
+		if p.lastPlain != -1 && indent > p.lastPlainIndent {
+			if _, _, isKey := splitYAMLKey(trimmed); isKey {
+				return nil, newSyntaxError(line, "bad indentation of a mapping entry")
+			}
+
+			p.entries[p.lastPlain].Value += " " + stripYAMLComment(trimmed)
+			continue
+		}
+		p.lastPlain = -1
+
+		err := p.parseNode(trimmed, indent, line)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p.entries, nil
+}
+
+func (p *yamlParser) parseNode(content string, indent int, line int) error {
+	// Close nested levels
+	for len(p.stack) > 1 && p.stack[len(p.stack)-1].indent >= indent {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+	parent := &p.stack[len(p.stack)-1]
+
+	var key string
+
+	if content == "-" || strings.HasPrefix(content, "- ") {
+		// Sequence item
+		key = joinKey(parent.prefix, strconv.Itoa(parent.seq))
+		parent.seq++
+
+		rest := strings.TrimLeft(content[1:], " ")
+		if rest == "" {
+			p.stack = append(p.stack, yamlLevel{indent: indent, prefix: key})
+			return nil
+		}
+
+		// Mapping inside sequence item: "- key: value"
+		if _, _, isKey := splitYAMLKey(rest); isKey {
+			itemIndent := indent + len(content) - len(rest)
+			p.stack = append(p.stack, yamlLevel{indent: indent, prefix: key})
+			return p.parseNode(rest, itemIndent, line)
+		}
+
+		return p.parseValue(key, rest, indent, line)
+	}
+
+	k, rest, isKey := splitYAMLKey(content)
+	if !isKey {
+		return newSyntaxError(line, "expected 'key: value': "+content)
+	}
+
+	key = joinKey(parent.prefix, k)
+
+	rest = stripYAMLComment(rest)
+	if rest == "" {
+		// Nested mapping or sequence
+		p.stack = append(p.stack, yamlLevel{indent: indent, prefix: key})
+		return nil
+	}
+
+	return p.parseValue(key, rest, indent, line)
+}
+
+func (p *yamlParser) parseValue(key string, value string, indent int, line int) error {
+	// Skip anchors and tags
+	for len(value) > 0 && (value[0] == '&' || value[0] == '!') {
+		end := strings.IndexByte(value, ' ')
+		if end == -1 {
+			value = ""
+			break
+		}
+		value = strings.TrimLeft(value[end:], " ")
+	}
+
+	entry := Entry{
+		Key:     key,
+		Comment: strings.Join(p.comment, "\n"),
+		Line:    line,
+	}
+	p.comment = nil
+
+	var err error
+	switch {
+	case value == "":
+
+	case value[0] == '"' || value[0] == '\'':
+		entry.Value, err = p.readQuoted(value, line)
+
+	case value[0] == '|' || value[0] == '>':
+		entry.Value = p.readBlock(value, indent)
+
+	case value[0] == '{' || value[0] == '[':
+		value = stripYAMLComment(value)
+		if !balancedBrackets(value) {
+			return newSyntaxError(line, "unbalanced brackets in flow collection: "+value)
+		}
+		entry.Value = value
+
+	case value[0] == '*':
+		entry.Value = stripYAMLComment(value)
+
+	default:
+		value = stripYAMLComment(value)
+		if strings.Contains(value, ": ") || strings.HasSuffix(value, ":") {
+			return newSyntaxError(line, "mapping values are not allowed here: "+value)
+		}
+
+		entry.Value = value
+		p.lastPlain = len(p.entries)
+		p.lastPlainIndent = indent
+	}
+	if err != nil {
+		return err
+	}
+
+	p.entries = append(p.entries, entry)
+	return nil
+}
+
+// readQuoted reads a single or double quoted scalar, which can take several lines.
+func (p *yamlParser) readQuoted(value string, line int) (string, error) {
+	quote := value[0]
+	text := value[1:]
+
+	var b strings.Builder
+	for {
+		for i := 0; i < len(text); i++ {
+			c := text[i]
+
+			if c == quote {
+				// Escaped single quote
+				if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+					b.WriteByte('\'')
+					i++
+					continue
+				}
+
+				rest := strings.TrimSpace(stripYAMLComment(text[i+1:]))
+				if rest != "" {
+					return "", newSyntaxError(p.pos+1, "unexpected characters after quoted string: "+rest)
+				}
+
+				return b.String(), nil
+			}
+
+			if c == '\\' && quote == '"' {
+				i++
+				if i >= len(text) {
+					// Escaped line break
+					break
+				}
+
+				switch text[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				case '0':
+					b.WriteByte(0)
+				case '\\', '"', '/', ' ':
+					b.WriteByte(text[i])
+				case 'u', 'U', 'x':
+					size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[i]]
+					if i+size >= len(text) {
+						return "", newSyntaxError(p.pos+1, "malformed escape sequence")
+					}
+
+					code, err := strconv.ParseUint(text[i+1:i+1+size], 16, 32)
+					if err != nil {
+						return "", newSyntaxError(p.pos+1, "malformed escape sequence: \\"+text[i:i+1+size])
+					}
+
+					b.WriteRune(rune(code))
+					i += size
+				default:
+					return "", newSyntaxError(p.pos+1, "unknown escape sequence: \\"+string(text[i]))
+				}
+				continue
+			}
+
+			b.WriteByte(c)
+		}
+
+		// String continues on the next line
+		p.pos++
+		if p.pos >= len(p.lines) {
+			return "", newSyntaxError(line, "unterminated quoted string")
+		}
+
+		b.WriteByte(' ')
+		text = strings.TrimSpace(p.lines[p.pos])
+	}
+}
+
+// readBlock reads a literal (|) or folded (>) block scalar.
+func (p *yamlParser) readBlock(header string, indent int) string {
+	literal := header[0] == '|'
+	keep := strings.Contains(header, "+")
+	strip := strings.Contains(header, "-")
+
+	var lines []string
+	blockIndent := -1
+	for p.pos+1 < len(p.lines) {
+		next := p.lines[p.pos+1]
+		trimmed := strings.TrimSpace(next)
+		nextIndent := len(next) - len(strings.TrimLeft(next, " "))
+
+		if trimmed != "" && nextIndent <= indent {
+			break
+		}
+
+		p.pos++
+		if trimmed == "" {
+			lines = append(lines, "")
+			continue
+		}
+
+		if blockIndent == -1 {
+			blockIndent = nextIndent
+		}
+
+		if nextIndent < blockIndent {
+			blockIndent = nextIndent
+		}
+
+		lines = append(lines, next[blockIndent:])
+	}
+
+	// Trailing empty lines
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var text string
+	if literal {
+		text = strings.Join(lines, "\n")
+	} else {
+		for i, line := range lines {
+			switch {
+			case i == 0:
+				text = line
+			case line == "" || lines[i-1] == "":
+				text += "\n" + line
+			default:
+				text += " " + line
+			}
+		}
+	}
+
+	switch {
+	case strip:
+	case keep:
+		text += strings.Repeat("\n", trailing+1)
+	default:
+		text += "\n"
+	}
+
+	return text
+}
+
+// splitYAMLKey splits "key: value" line.
+func splitYAMLKey(s string) (string, string, bool) {
+	// Quoted key
+	if len(s) > 0 && (s[0] == '"' || s[0] == '\'') {
+		end := strings.IndexByte(s[1:], s[0])
+		if end == -1 {
+			return "", "", false
+		}
+
+		rest := s[end+2:]
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", false
+		}
+
+		rest = rest[1:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+
+		return s[1 : end+1], strings.TrimSpace(rest), true
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && i > 0 && s[i-1] == ' ' {
+			return "", "", false
+		}
+
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ') {
+			key := strings.TrimSpace(s[:i])
+			if key == "" || strings.ContainsAny(key[:1], "\"'[]{}|>*&!%@`") {
+				return "", "", false
+			}
+
+			return key, strings.TrimSpace(s[i+1:]), true
+		}
+	}
+
+	return "", "", false
+}
+
+// stripYAMLComment removes comment from the unquoted value.
+// Quotes only start a quoted scalar at the beginning of the value or of a flow collection item,
+// so apostrophes in plain scalars like "It's fine" are ordinary characters.
+func stripYAMLComment(s string) string {
+	inSingle, inDouble := false, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case inDouble:
+			if s[i] == '\\' {
+				i++
+			} else if s[i] == '"' {
+				inDouble = false
+			}
+		case inSingle:
+			if s[i] == '\'' {
+				inSingle = false
+			}
+		case (s[i] == '"' || s[i] == '\'') && scalarStart(s[:i]):
+			inDouble = s[i] == '"'
+			inSingle = s[i] == '\''
+		case s[i] == '#' && (i == 0 || s[i-1] == ' '):
+			return strings.TrimSpace(s[:i])
+		}
+	}
+
+	return strings.TrimSpace(s)
+}
+
+// scalarStart returns true if a scalar can start after the prefix.
+func scalarStart(prefix string) bool {
+	prefix = strings.TrimRight(prefix, " \t")
+	return prefix == "" || strings.ContainsAny(prefix[len(prefix)-1:], "[{,:")
+}
+
+func balancedBrackets(s string) bool {
+	var stack []byte
+	inQuote := byte(0)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inQuote != 0:
+			if c == '\\' && inQuote == '"' {
+				i++
+			} else if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+		case c == '{' || c == '[':
+			stack = append(stack, c)
+		case c == '}' || c == ']':
+			if len(stack) == 0 {
+				return false
+			}
+
+			open := stack[len(stack)-1]
+			if (c == '}' && open != '{') || (c == ']' && open != '[') {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return len(stack) == 0 && inQuote == 0
+}